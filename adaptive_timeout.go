@@ -0,0 +1,119 @@
+package raccoon_kv_client
+
+import (
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// latencyWindow is the number of recent latencies an adaptive timeout is computed from.
+	latencyWindow = 1000
+	// minLatencySamples is the number of latencies needed before adaptive timeouts replace the fixed ones.
+	minLatencySamples = 20
+)
+
+// AdaptiveTimeout derives request timeouts from recently observed latencies instead of fixed values. Reads and
+// writes are tracked separately, watches are not tracked. Until enough requests have been observed the fixed
+// timeouts apply.
+type AdaptiveTimeout struct {
+	// Percentile of the observed latencies the timeout is based on. It defaults to 0.99.
+	Percentile float64
+	// Factor multiplies the percentile to get the timeout. It defaults to 3.
+	Factor float64
+	// Min and Max bound the timeout. They default to 100 milliseconds and one minute.
+	Min time.Duration
+	Max time.Duration
+}
+
+// WithAdaptiveTimeout bounds reads and writes by timeouts derived from their observed latencies. Latencies are
+// measured up to the response headers, so reads are only bounded by it until the server responds; the transfer of
+// the value is bounded by the fixed read timeout. Timeouts given for a single call still take precedence.
+func WithAdaptiveTimeout(config AdaptiveTimeout) Option {
+	return func(c *Client) {
+		if config.Percentile <= 0 || config.Percentile > 1 {
+			config.Percentile = 0.99
+		}
+		if config.Factor <= 0 {
+			config.Factor = 3
+		}
+		if config.Min <= 0 {
+			config.Min = 100 * time.Millisecond
+		}
+		if config.Max <= 0 {
+			config.Max = time.Minute
+		}
+
+		c.adaptive = &adaptiveTimeouts{
+			config: config,
+			reads:  &latencies{},
+			writes: &latencies{},
+		}
+	}
+}
+
+type adaptiveTimeouts struct {
+	config AdaptiveTimeout
+	reads  *latencies
+	writes *latencies
+}
+
+// observe records how long request took to be answered, ignoring watches and failed requests.
+func (a *adaptiveTimeouts) observe(request *http.Request, response *http.Response, err error, latency time.Duration) {
+	if err != nil || response.StatusCode >= http.StatusInternalServerError || retriesDisabled(request.Context()) {
+		return
+	}
+
+	if request.Method == "GET" || request.Method == "HEAD" {
+		a.reads.add(latency)
+	} else {
+		a.writes.add(latency)
+	}
+}
+
+// timeout returns the adaptive timeout for requests tracked by l, or zero if too few have been observed.
+func (a *adaptiveTimeouts) timeout(l *latencies) time.Duration {
+	percentile, ok := l.percentile(a.config.Percentile)
+	if !ok {
+		return 0
+	}
+
+	timeout := time.Duration(float64(percentile) * a.config.Factor)
+
+	return min(max(timeout, a.config.Min), a.config.Max)
+}
+
+// latencies is a ring buffer of the most recent request latencies.
+type latencies struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+}
+
+func (l *latencies) add(latency time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.samples) < latencyWindow {
+		l.samples = append(l.samples, latency)
+		return
+	}
+
+	l.samples[l.next] = latency
+	l.next = (l.next + 1) % latencyWindow
+}
+
+func (l *latencies) percentile(p float64) (time.Duration, bool) {
+	l.mu.Lock()
+	sorted := slices.Clone(l.samples)
+	l.mu.Unlock()
+
+	if len(sorted) < minLatencySamples {
+		return 0, false
+	}
+
+	slices.Sort(sorted)
+
+	return sorted[min(int(p*float64(len(sorted))), len(sorted)-1)], true
+}
@@ -0,0 +1,75 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// slowServer answers immediately, except that the body of "slow-body" trickles in after a delay and the headers of
+// "slow-headers" are only sent after one.
+func slowServer(delay time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("etag", "1")
+
+		switch r.URL.Path {
+		case "/kv/slow-body":
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+			time.Sleep(delay)
+		case "/kv/slow-headers":
+			time.Sleep(delay)
+		}
+
+		_, _ = w.Write([]byte("value"))
+	}))
+}
+
+func trainedClient(t *testing.T, serverUrl string) *Client {
+	c, err := NewClient(serverUrl, WithAdaptiveTimeout(AdaptiveTimeout{Min: 20 * time.Millisecond, Max: 50 * time.Millisecond}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range minLatencySamples {
+		if _, _, err := c.Get(context.Background(), "fast"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return c
+}
+
+func TestAdaptiveTimeoutLeavesBodyTransfer(t *testing.T) {
+	server := slowServer(200 * time.Millisecond)
+	defer server.Close()
+
+	c := trainedClient(t, server.URL)
+
+	data, _, err := c.Get(context.Background(), "slow-body")
+	if err != nil {
+		t.Fatalf("Get = %v, want the value", err)
+	}
+
+	if string(data) != "value" {
+		t.Errorf("Get = %q, want %q", data, "value")
+	}
+}
+
+func TestAdaptiveTimeoutBoundsHeaderWait(t *testing.T) {
+	server := slowServer(200 * time.Millisecond)
+	defer server.Close()
+
+	c := trainedClient(t, server.URL)
+
+	if _, _, err := c.Get(context.Background(), "slow-headers"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Get = %v, want context.DeadlineExceeded", err)
+	}
+
+	if _, _, err := c.GetWithOptions(context.Background(), "slow-headers", Timeout(time.Second)); err != nil {
+		t.Errorf("Get with a call timeout = %v, want the value", err)
+	}
+}
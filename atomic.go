@@ -0,0 +1,123 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Increment atomically adds delta to the integer stored at key and returns the new value. A missing key is
+// treated as zero.
+func (c *Client) Increment(ctx context.Context, key string, delta int64) (int64, error) {
+	var result int64
+
+	err := c.update(ctx, key, func(current []byte, exists bool) ([]byte, error) {
+		var value int64
+
+		if exists {
+			parsed, err := strconv.ParseInt(strings.TrimSpace(string(current)), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("value of %s is not an integer: %w", key, err)
+			}
+
+			value = parsed
+		}
+
+		result = value + delta
+
+		return []byte(strconv.FormatInt(result, 10)), nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return result, nil
+}
+
+// Decrement atomically subtracts delta from the integer stored at key and returns the new value.
+func (c *Client) Decrement(ctx context.Context, key string, delta int64) (int64, error) {
+	return c.Increment(ctx, key, -delta)
+}
+
+// Append atomically appends data to the value stored at key, creating the key if it does not exist.
+func (c *Client) Append(ctx context.Context, key string, data []byte) error {
+	return c.update(ctx, key, func(current []byte, exists bool) ([]byte, error) {
+		next := make([]byte, 0, len(current)+len(data))
+		next = append(next, current...)
+
+		return append(next, data...), nil
+	})
+}
+
+// update performs a read-modify-write of key, retrying whenever a concurrent writer changes the key between
+// the read and the conditional write.
+func (c *Client) update(ctx context.Context, key string, modify func(current []byte, exists bool) ([]byte, error)) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		data, version, err := c.Get(ctx, key)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+
+		exists := err == nil
+
+		next, err := modify(data, exists)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			err = c.PutIfVersion(ctx, key, next, version)
+		} else {
+			err = c.Create(ctx, key, next)
+		}
+
+		if errors.Is(err, ErrConflict) || errors.Is(err, ErrAlreadyExists) {
+			continue
+		}
+
+		return err
+	}
+}
+
+// Move atomically renames src to dst. It returns ErrNotFound if src does not exist and ErrAlreadyExists if dst
+// does; if src changes while the move is in progress the move is retried against the new value.
+func (c *Client) Move(ctx context.Context, src string, dst string) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		data, version, err := c.Get(ctx, src)
+		if err != nil {
+			return err
+		}
+
+		succeeded, err := c.Txn().
+			IfVersion(src, version).
+			IfAbsent(dst).
+			Then(OpPut(dst, data), OpDelete(src)).
+			Commit(ctx)
+		if err != nil {
+			return err
+		}
+
+		if succeeded {
+			return nil
+		}
+
+		exists, _, _, err := c.Head(ctx, dst)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			return ErrAlreadyExists
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"net/http"
+)
+
+// AuditRecord describes a write or delete made through the client, for recording who changed what.
+type AuditRecord struct {
+	// Operation is "put" or "delete".
+	Operation string
+	Key       string
+	// Size is the number of bytes written, zero for a delete or a copy.
+	Size int
+	// PreviousVersion is the version the key had before the change, empty if it did not exist.
+	PreviousVersion string
+	// Version is the version the server assigned to the written value, empty for a delete, a failed write or a
+	// write made in a transaction, for which the server reports no versions.
+	Version string
+	// Annotation is the caller-supplied annotation given with Annotate, e.g. the user on whose behalf the change
+	// was made.
+	Annotation string
+	// Err is the error the operation failed with, if any.
+	Err error
+}
+
+// WithAudit calls audit after every Put and Delete, including conditional ones, copies and those made by
+// read-modify-write helpers, whether or not they succeed. Transactions, and helpers built on them such as Move, report
+// the writes of the branch that was executed, or of the Then branch if the commit failed. Changes whose previous
+// version is not pinned by a condition cost an extra HEAD request to look it up.
+func WithAudit(audit func(AuditRecord)) Option {
+	return func(c *Client) {
+		c.audit = audit
+	}
+}
+
+// Annotate attaches annotation to the audit record of one operation.
+func Annotate(annotation string) CallOption {
+	return func(config *callConfig) {
+		config.annotation = annotation
+	}
+}
+
+// auditRecord starts the audit record of a change to key made with the precondition headers in header, looking up
+// the previous version if the precondition does not pin it.
+func (c *Client) auditRecord(ctx context.Context, operation string, key string, header http.Header) AuditRecord {
+	record := AuditRecord{
+		Operation:  operation,
+		Key:        key,
+		Annotation: callOptions(ctx).annotation,
+	}
+
+	switch {
+	case header.Get("if-match") != "":
+		record.PreviousVersion = header.Get("if-match")
+	case header.Get("if-none-match") == "*":
+	default:
+		if exists, version, _, err := c.Head(ctx, key); err == nil && exists {
+			record.PreviousVersion = version
+		}
+	}
+
+	return record
+}
+
+// finishAudit completes record with the outcome of the change and reports it.
+func (c *Client) finishAudit(record AuditRecord, responseHeader http.Header, err error) {
+	record.Err = err
+
+	if err == nil && record.Operation == "put" {
+		record.Version = responseHeader.Get("etag")
+	}
+
+	c.audit(record)
+}
+
+// auditOps starts the audit records of the writes in ops, taking the previous versions pinned by the transaction's
+// conditions from them.
+func (c *Client) auditOps(ctx context.Context, conditions []txnCondition, ops []TxnOp) []AuditRecord {
+	records := make([]AuditRecord, 0, len(ops))
+
+	for _, op := range ops {
+		header := http.Header{}
+
+		for _, condition := range conditions {
+			switch {
+			case condition.Key != op.Key:
+			case condition.Absent:
+				header.Set("if-none-match", "*")
+			default:
+				header.Set("if-match", condition.Version)
+			}
+		}
+
+		record := c.auditRecord(ctx, op.Op, op.Key, header)
+		record.Size = len(op.Value)
+
+		records = append(records, record)
+	}
+
+	return records
+}
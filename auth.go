@@ -0,0 +1,145 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// CredentialsProvider supplies bearer tokens for requests. The client caches the returned token and only asks
+// again once the server rejects it with 401 Unauthorized.
+type CredentialsProvider interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// WithBearerToken authenticates every request, including watches, with an "Authorization: Bearer" header.
+func WithBearerToken(token string) Option {
+	return func(c *Client) {
+		c.bearerToken = token
+	}
+}
+
+// WithCredentialsProvider authenticates every request with a bearer token from provider, taking precedence over
+// WithBearerToken. A request rejected with 401 is retried once with a freshly fetched token.
+func WithCredentialsProvider(provider CredentialsProvider) Option {
+	return func(c *Client) {
+		c.credentials = &credentialsCache{provider: provider}
+	}
+}
+
+// WithBasicAuth authenticates every request with HTTP basic auth.
+func WithBasicAuth(username string, password string) Option {
+	return func(c *Client) {
+		c.basicAuth = &basicAuth{username: username, password: password}
+	}
+}
+
+// WithAPIKey authenticates every request by setting header to value, for deployments that expect a key in a
+// custom header such as X-Raccoon-Key.
+func WithAPIKey(header string, value string) Option {
+	return func(c *Client) {
+		c.apiKey = &apiKey{header: header, value: value}
+	}
+}
+
+type apiKey struct {
+	header string
+	value  string
+}
+
+type basicAuth struct {
+	username string
+	password string
+}
+
+// credentialsCache holds the last token returned by a CredentialsProvider. Fetches are serialized so a burst of
+// requests after invalidation asks the provider only once.
+type credentialsCache struct {
+	provider CredentialsProvider
+
+	mu    sync.Mutex
+	token string
+}
+
+func (c *credentialsCache) get(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" {
+		return c.token, nil
+	}
+
+	token, err := c.provider.Token(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	c.token = token
+
+	return token, nil
+}
+
+// invalidate drops token from the cache unless another request already replaced it with a newer one.
+func (c *credentialsCache) invalidate(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token == token {
+		c.token = ""
+	}
+}
+
+// authorize attaches the client's credentials to request.
+func (c *Client) authorize(request *http.Request) error {
+	if c.bearerToken != "" {
+		request.Header.Set("authorization", "Bearer "+c.bearerToken)
+	}
+
+	if c.credentials != nil {
+		token, err := c.credentials.get(request.Context())
+		if err != nil {
+			return err
+		}
+
+		request.Header.Set("authorization", "Bearer "+token)
+	}
+
+	if c.basicAuth != nil {
+		request.SetBasicAuth(c.basicAuth.username, c.basicAuth.password)
+	}
+
+	if c.apiKey != nil {
+		request.Header.Set(c.apiKey.header, c.apiKey.value)
+	}
+
+	return nil
+}
+
+// reauthorize prepares a retry of request after a 401, invalidating the token it was sent with. It returns nil if
+// the request cannot be retried, either because no credentials provider is configured or because its body cannot
+// be replayed.
+func (c *Client) reauthorize(request *http.Request) (*http.Request, error) {
+	if c.credentials == nil || (request.Body != nil && request.GetBody == nil) {
+		return nil, nil
+	}
+
+	c.credentials.invalidate(strings.TrimPrefix(request.Header.Get("authorization"), "Bearer "))
+
+	retry := request.Clone(request.Context())
+
+	if request.GetBody != nil {
+		body, err := request.GetBody()
+		if err != nil {
+			return nil, err
+		}
+
+		retry.Body = body
+	}
+
+	if err := c.prepare(retry); err != nil {
+		return nil, err
+	}
+
+	return retry, nil
+}
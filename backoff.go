@@ -0,0 +1,64 @@
+package raccoon_kv_client
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff decides how long to wait before retrying after a failure.
+type Backoff interface {
+	// Delay returns the wait before the next attempt, given the number of consecutive failures so far, starting
+	// at one.
+	Delay(failures int) time.Duration
+}
+
+// ExponentialBackoff waits Initial after the first failure and multiplies the wait by Multiplier after each
+// further failure, never exceeding Max. A zero Initial waits one second, a Multiplier of one or less doubles the
+// wait and a zero or negative Max leaves it uncapped; for a constant wait, set Max to Initial.
+type ExponentialBackoff struct {
+	Initial    time.Duration
+	Max        time.Duration
+	Multiplier float64
+	// Jitter picks each wait uniformly between zero and the computed delay ("full jitter"), so that clients
+	// failing at the same moment do not all retry in lockstep.
+	Jitter bool
+}
+
+// DefaultBackoff is used when neither the client nor the watch configures a Backoff.
+var DefaultBackoff = ExponentialBackoff{
+	Initial:    time.Second,
+	Max:        time.Minute,
+	Multiplier: 2,
+	Jitter:     true,
+}
+
+func (b ExponentialBackoff) Delay(failures int) time.Duration {
+	initial := b.Initial
+	if initial <= 0 {
+		initial = DefaultBackoff.Initial
+	}
+
+	multiplier := b.Multiplier
+	if multiplier <= 1 {
+		multiplier = DefaultBackoff.Multiplier
+	}
+
+	limit := float64(math.MaxInt64)
+	if b.Max > 0 {
+		limit = float64(b.Max)
+	}
+
+	delay := time.Duration(math.MaxInt64)
+	if exponential := float64(initial) * math.Pow(multiplier, float64(max(failures, 1)-1)); exponential < limit {
+		delay = time.Duration(exponential)
+	} else if b.Max > 0 {
+		delay = b.Max
+	}
+
+	if b.Jitter && delay > 0 {
+		return rand.N(delay)
+	}
+
+	return delay
+}
@@ -0,0 +1,43 @@
+package raccoon_kv_client
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExponentialBackoffDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		backoff  ExponentialBackoff
+		failures int
+		want     time.Duration
+	}{
+		{"first failure", ExponentialBackoff{Initial: time.Second, Max: time.Minute, Multiplier: 2}, 1, time.Second},
+		{"grows", ExponentialBackoff{Initial: time.Second, Max: time.Minute, Multiplier: 2}, 4, 8 * time.Second},
+		{"capped", ExponentialBackoff{Initial: time.Second, Max: time.Minute, Multiplier: 2}, 10, time.Minute},
+		{"zero max is uncapped", ExponentialBackoff{Initial: time.Second, Multiplier: 2}, 10, 512 * time.Second},
+		{"zero initial", ExponentialBackoff{Max: time.Minute, Multiplier: 2}, 1, time.Second},
+		{"multiplier of one", ExponentialBackoff{Initial: time.Second, Max: time.Minute, Multiplier: 1}, 3, 4 * time.Second},
+		{"zero multiplier", ExponentialBackoff{Initial: time.Second, Max: time.Minute}, 3, 4 * time.Second},
+		{"constant", ExponentialBackoff{Initial: time.Second, Max: time.Second, Multiplier: 2}, 5, time.Second},
+		{"huge failure count", ExponentialBackoff{Initial: time.Second, Multiplier: 2}, 10000, time.Duration(1<<63 - 1)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.backoff.Delay(test.failures); got != test.want {
+				t.Errorf("Delay(%d) = %s, want %s", test.failures, got, test.want)
+			}
+		})
+	}
+}
+
+func TestExponentialBackoffJitter(t *testing.T) {
+	backoff := ExponentialBackoff{Initial: time.Second, Max: time.Minute, Multiplier: 2, Jitter: true}
+
+	for range 100 {
+		if got := backoff.Delay(3); got < 0 || got > 4*time.Second {
+			t.Fatalf("Delay(3) = %s, want between 0 and 4s", got)
+		}
+	}
+}
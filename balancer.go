@@ -0,0 +1,91 @@
+package raccoon_kv_client
+
+import (
+	"math/rand/v2"
+	"sync/atomic"
+)
+
+// EndpointStatus describes one of the client's endpoints.
+type EndpointStatus struct {
+	Url     string
+	Healthy bool
+	// Pending is the number of requests currently in flight to the endpoint.
+	Pending int
+}
+
+// Balancer spreads read requests across endpoints. Pick is given the healthy endpoints in their configured order
+// and returns the index of the one to try first; the others remain failover targets.
+type Balancer interface {
+	Pick(candidates []EndpointStatus) int
+}
+
+// WithBalancer spreads reads across the endpoints given with WithEndpoints according to balancer. Writes always
+// go to the first healthy endpoint in configured order.
+func WithBalancer(balancer Balancer) Option {
+	return func(c *Client) {
+		c.balancer = balancer
+	}
+}
+
+// RoundRobin returns a Balancer that cycles through the endpoints.
+func RoundRobin() Balancer {
+	return &roundRobin{}
+}
+
+type roundRobin struct {
+	next atomic.Uint64
+}
+
+func (b *roundRobin) Pick(candidates []EndpointStatus) int {
+	return int((b.next.Add(1) - 1) % uint64(len(candidates)))
+}
+
+// LeastPending returns a Balancer that picks the endpoint with the fewest requests in flight, preferring earlier
+// endpoints on ties.
+func LeastPending() Balancer {
+	return leastPending{}
+}
+
+type leastPending struct{}
+
+func (leastPending) Pick(candidates []EndpointStatus) int {
+	best := 0
+
+	for i, candidate := range candidates {
+		if candidate.Pending < candidates[best].Pending {
+			best = i
+		}
+	}
+
+	return best
+}
+
+// Weighted returns a Balancer that picks endpoints at random in proportion to their weight, keyed by endpoint
+// URL. Endpoints without a positive weight are only used for failover.
+func Weighted(weights map[string]int) Balancer {
+	return weighted(weights)
+}
+
+type weighted map[string]int
+
+func (w weighted) Pick(candidates []EndpointStatus) int {
+	total := 0
+	for _, candidate := range candidates {
+		total += max(w[candidate.Url], 0)
+	}
+
+	if total == 0 {
+		return 0
+	}
+
+	n := rand.IntN(total)
+
+	for i, candidate := range candidates {
+		n -= max(w[candidate.Url], 0)
+		if n < 0 {
+			return i
+		}
+	}
+
+	return 0
+}
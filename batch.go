@@ -0,0 +1,115 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// batchConcurrency bounds the number of requests a batch operation keeps in flight.
+const batchConcurrency = 16
+
+// GetResult is the outcome of a single key within BatchGet.
+type GetResult struct {
+	Data    []byte
+	Version string
+	Err     error
+}
+
+// BatchGet fetches keys concurrently and returns one result per distinct key. Missing keys have ErrNotFound as
+// their Err.
+func (c *Client) BatchGet(ctx context.Context, keys []string) map[string]GetResult {
+	results := make(map[string]GetResult, len(keys))
+
+	var mu sync.Mutex
+
+	forEachConcurrently(keys, func(key string) {
+		data, version, err := c.Get(ctx, key)
+
+		mu.Lock()
+		results[key] = GetResult{Data: data, Version: version, Err: err}
+		mu.Unlock()
+	})
+
+	return results
+}
+
+// BatchError collects the per-key failures of a batch write.
+type BatchError struct {
+	Errors map[string]error
+}
+
+func (e *BatchError) Error() string {
+	keys := make([]string, 0, len(e.Errors))
+	for key := range e.Errors {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	messages := make([]string, 0, len(keys))
+	for _, key := range keys {
+		messages = append(messages, fmt.Sprintf("%s: %s", key, e.Errors[key]))
+	}
+
+	return fmt.Sprintf("%d keys failed: %s", len(e.Errors), strings.Join(messages, "; "))
+}
+
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+// BatchPut writes all entries concurrently. If any write fails, the returned error is a *BatchError
+// holding the failure for each affected key; successful writes are not rolled back.
+func (c *Client) BatchPut(ctx context.Context, entries map[string][]byte) error {
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+
+	failures := map[string]error{}
+
+	var mu sync.Mutex
+
+	forEachConcurrently(keys, func(key string) {
+		if err := c.Put(ctx, key, entries[key]); err != nil {
+			mu.Lock()
+			failures[key] = err
+			mu.Unlock()
+		}
+	})
+
+	if len(failures) > 0 {
+		return &BatchError{Errors: failures}
+	}
+
+	return nil
+}
+
+func forEachConcurrently(keys []string, fn func(key string)) {
+	semaphore := make(chan struct{}, batchConcurrency)
+
+	var wg sync.WaitGroup
+
+	for _, key := range keys {
+		semaphore <- struct{}{}
+		wg.Add(1)
+
+		go func() {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+
+			fn(key)
+		}()
+	}
+
+	wg.Wait()
+}
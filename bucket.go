@@ -0,0 +1,121 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"strings"
+)
+
+// Bucket is a view of the keys under name/ on a client, like a bucket of an object store. Keys passed to and
+// returned by a bucket are relative to it, and its default options apply to every operation through it.
+type Bucket struct {
+	client   *Client
+	name     string
+	prefix   string
+	defaults []CallOption
+}
+
+// Bucket returns a view of the keys under name/, within the client's namespace, that applies opts to every
+// operation unless a call overrides them. An invalid name makes every operation fail with ErrInvalidKey.
+func (c *Client) Bucket(name string, opts ...CallOption) *Bucket {
+	return &Bucket{client: c, name: name, prefix: name + "/", defaults: opts}
+}
+
+// Name returns the name of the bucket.
+func (b *Bucket) Name() string {
+	return b.name
+}
+
+// Get returns the value of key in the bucket and its version. It returns ErrNotFound if the key does not exist.
+func (b *Bucket) Get(ctx context.Context, key string, opts ...CallOption) (data []byte, version string, err error) {
+	return b.client.Get(b.context(ctx, opts), b.prefix+key)
+}
+
+// GetEntry returns the value of key in the bucket along with its metadata, with Key relative to the bucket.
+func (b *Bucket) GetEntry(ctx context.Context, key string, opts ...CallOption) (*Entry, error) {
+	entry, err := b.client.GetEntry(b.context(ctx, opts), b.prefix+key)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.Key = key
+
+	return entry, nil
+}
+
+// Put writes data to key in the bucket.
+func (b *Bucket) Put(ctx context.Context, key string, data []byte, opts ...CallOption) error {
+	return b.client.Put(b.context(ctx, opts), b.prefix+key, data)
+}
+
+// Delete removes key from the bucket.
+func (b *Bucket) Delete(ctx context.Context, key string, opts ...CallOption) error {
+	return b.client.Delete(b.context(ctx, opts), b.prefix+key)
+}
+
+// List returns the keys in the bucket starting with prefix, relative to the bucket.
+func (b *Bucket) List(ctx context.Context, prefix string, opts ...CallOption) ([]KeyVersion, error) {
+	keys, err := b.client.List(b.context(ctx, opts), b.prefix+prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range keys {
+		keys[i].Key = strings.TrimPrefix(keys[i].Key, b.prefix)
+	}
+
+	return keys, nil
+}
+
+// GetJSON fetches key in the bucket and decodes its JSON value into v, as Client.GetJSON does.
+func (b *Bucket) GetJSON(ctx context.Context, key string, v any, opts ...CallOption) error {
+	return b.client.GetJSON(b.context(ctx, opts), b.prefix+key, v)
+}
+
+// PutJSON encodes v as JSON and writes it to key in the bucket, as Client.PutJSON does.
+func (b *Bucket) PutJSON(ctx context.Context, key string, v any, opts ...CallOption) error {
+	return b.client.PutJSON(b.context(ctx, opts), b.prefix+key, v)
+}
+
+// GetValue fetches key in the bucket and decodes its value into v with the codec for its content type, as
+// Client.GetValue does.
+func (b *Bucket) GetValue(ctx context.Context, key string, v any, opts ...CallOption) error {
+	return b.client.GetValue(b.context(ctx, opts), b.prefix+key, v)
+}
+
+// PutValue encodes v with the codec registered for contentType and writes it to key in the bucket, as
+// Client.PutValue does.
+func (b *Bucket) PutValue(ctx context.Context, key string, v any, contentType string, opts ...CallOption) error {
+	return b.client.PutValue(b.context(ctx, opts), b.prefix+key, v, contentType)
+}
+
+// Watch watches key in the bucket and calls cb with its new value whenever it changes.
+func (b *Bucket) Watch(ctx context.Context, key string, cb func([]byte), opts ...WatchOption) {
+	b.client.Watch(b.context(ctx, nil), b.prefix+key, cb, opts...)
+}
+
+// WatchEvents watches key in the bucket and calls cb with a typed Event, keyed relative to the bucket, for every
+// change, starting with the current state.
+func (b *Bucket) WatchEvents(ctx context.Context, key string, cb func(Event), opts ...WatchOption) {
+	b.client.WatchEvents(b.context(ctx, nil), b.prefix+key, func(event Event) {
+		event.Key = key
+		cb(event)
+	}, opts...)
+}
+
+// context returns ctx with the bucket's default options applied, followed by the options of the call.
+func (b *Bucket) context(ctx context.Context, opts []CallOption) context.Context {
+	if len(b.defaults) == 0 && len(opts) == 0 {
+		return ctx
+	}
+
+	return ContextWithCallOptions(ctx, append(b.defaults[:len(b.defaults):len(b.defaults)], opts...)...)
+}
+
+// TypedBucket is Typed for the keys of bucket b, applying its default options.
+func TypedBucket[T any](b *Bucket, codec Codec) *TypedClient[T] {
+	typed := Typed[T](b.client, codec)
+	typed.prefix = b.prefix
+	typed.defaults = b.defaults
+
+	return typed
+}
@@ -0,0 +1,272 @@
+package raccoon_kv_client
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+// valueCache holds the values Get has read, keyed by key.
+type valueCache struct {
+	// maxStale is how old a cached value may be and still be returned while it is revalidated in the background.
+	maxStale time.Duration
+	// negativeTTL is how long a key found missing is reported missing without asking the server.
+	negativeTTL time.Duration
+	// maxEntries and maxBytes bound the cache, if positive, by evicting the least recently used keys.
+	maxEntries int
+	maxBytes   int64
+
+	mu         sync.Mutex
+	entries    map[string]*cacheEntry
+	refreshing map[string]bool
+	// recency orders the cached keys from most to least recently used.
+	recency  *list.List
+	elements map[string]*list.Element
+	bytes    int64
+	stats    CacheStats
+	// generation counts invalidations, so that a read that raced with a write does not cache what it read.
+	generation uint64
+	// watches keeps the cached keys watched with WithCacheWatches or StartCacheWatches.
+	watches *cacheWatches
+}
+
+// cacheEntry is a cached value. Entries are replaced rather than modified.
+type cacheEntry struct {
+	data        []byte
+	version     string
+	validatedAt time.Time
+	// missing records that the key did not exist, with version being the one the server reported for it.
+	missing bool
+}
+
+// WithCache keeps the values Get reads in memory. Get then asks the server only whether a cached value has
+// changed, with If-None-Match, and the value is downloaded again only if it has. Writes and deletes made through
+// the client drop the affected keys from the cache.
+func WithCache() Option {
+	return func(c *Client) {
+		c.initCache()
+	}
+}
+
+// WithStaleWhileRevalidate enables the cache and lets Get return a cached value that was last confirmed by the
+// server at most maxStale ago without waiting for the server, revalidating it in the background instead. Older
+// values are revalidated before they are returned, as without this option.
+func WithStaleWhileRevalidate(maxStale time.Duration) Option {
+	return func(c *Client) {
+		c.initCache()
+		c.cache.maxStale = maxStale
+	}
+}
+
+// WithNegativeCache enables the cache and remembers for ttl that a key does not exist, so that repeated Gets of a
+// missing key return ErrNotFound without asking the server. Writing the key through the client forgets it at once.
+func WithNegativeCache(ttl time.Duration) Option {
+	return func(c *Client) {
+		c.initCache()
+		c.cache.negativeTTL = ttl
+	}
+}
+
+func (c *Client) initCache() {
+	if c.cache == nil {
+		c.cache = &valueCache{
+			entries:    map[string]*cacheEntry{},
+			refreshing: map[string]bool{},
+			recency:    list.New(),
+			elements:   map[string]*list.Element{},
+		}
+	}
+}
+
+// getCached is Get for a client with a cache, counting whether the value was served from the cache.
+func (c *Client) getCached(ctx context.Context, key string, keyUrl string) ([]byte, string, error) {
+	entry, cached := c.cache.lookup(key)
+
+	data, version, hit, err := c.readCache(ctx, key, keyUrl, entry, cached)
+	if err == nil || errors.Is(err, ErrNotFound) {
+		c.cache.count(hit)
+	}
+
+	return data, version, err
+}
+
+// readCache returns the value of key from entry if it can be trusted, and revalidates it otherwise. hit reports
+// whether the value was served without downloading it.
+func (c *Client) readCache(ctx context.Context, key string, keyUrl string, entry *cacheEntry, cached bool) (data []byte, version string, hit bool, err error) {
+
+	if cached && !entry.validatedAt.IsZero() && c.cache.currentWatches().connected(key) {
+		if entry.missing {
+			return nil, entry.version, true, ErrNotFound
+		}
+
+		return slices.Clone(entry.data), entry.version, true, nil
+	}
+
+	if cached && entry.missing && time.Since(entry.validatedAt) < c.cache.negativeTTL {
+		return nil, entry.version, true, ErrNotFound
+	}
+
+	if cached && !entry.missing && time.Since(entry.validatedAt) <= c.cache.maxStale {
+		if c.cache.startRefresh(key) {
+			go func() {
+				defer c.cache.endRefresh(key)
+
+				_, _, _ = c.revalidate(context.WithoutCancel(ctx), key, keyUrl, entry)
+			}()
+		}
+
+		return slices.Clone(entry.data), entry.version, true, nil
+	}
+
+	data, version, err = c.revalidate(ctx, key, keyUrl, entry)
+
+	// The server only confirms the cached version if it has not changed, without sending the value again.
+	return data, version, cached && version == entry.version, err
+}
+
+// revalidate fetches key unless it is still at the version of entry, which may be nil, and updates the cache.
+func (c *Client) revalidate(ctx context.Context, key string, keyUrl string, entry *cacheEntry) ([]byte, string, error) {
+	lastVersion := ""
+	if entry != nil {
+		lastVersion = entry.version
+	}
+
+	generation := c.cache.currentGeneration()
+
+	data, version, err := c.doRequest(withHedging(ctx), key, keyUrl, lastVersion, c.readTimeoutFor(ctx))
+	switch {
+	case err != nil:
+		return nil, "", err
+	case entry != nil && data == nil && version == lastVersion:
+		c.cache.store(key, &cacheEntry{data: entry.data, version: entry.version, validatedAt: time.Now(), missing: entry.missing}, generation)
+
+		if entry.missing {
+			return nil, entry.version, ErrNotFound
+		}
+
+		return slices.Clone(entry.data), entry.version, nil
+	case data == nil && c.cache.negativeTTL > 0:
+		c.cache.store(key, &cacheEntry{version: version, validatedAt: time.Now(), missing: true}, generation)
+		return nil, version, ErrNotFound
+	case data == nil:
+		c.cache.drop(key, generation)
+		return nil, version, ErrNotFound
+	}
+
+	c.cache.store(key, &cacheEntry{data: slices.Clone(data), version: version, validatedAt: time.Now()}, generation)
+
+	return data, version, nil
+}
+
+func (vc *valueCache) lookup(key string) (*cacheEntry, bool) {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	entry, ok := vc.entries[key]
+	if ok {
+		vc.recency.MoveToFront(vc.elements[key])
+	}
+
+	return entry, ok
+}
+
+func (vc *valueCache) currentGeneration() uint64 {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	return vc.generation
+}
+
+// store caches entry for key unless the cache was invalidated since generation, evicting the least recently used
+// keys if the cache grows beyond its limits.
+func (vc *valueCache) store(key string, entry *cacheEntry, generation uint64) {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	if vc.generation != generation {
+		return
+	}
+
+	vc.remove(key)
+
+	vc.entries[key] = entry
+	vc.elements[key] = vc.recency.PushFront(key)
+	vc.bytes += entrySize(key, entry)
+
+	for (vc.maxEntries > 0 && len(vc.entries) > vc.maxEntries) || (vc.maxBytes > 0 && vc.bytes > vc.maxBytes) {
+		oldest := vc.recency.Back().Value.(string)
+
+		vc.remove(oldest)
+		vc.watches.unwatch(oldest)
+		vc.stats.Evictions++
+	}
+}
+
+// drop removes key from the cache unless the cache was invalidated since generation.
+func (vc *valueCache) drop(key string, generation uint64) {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	if vc.generation == generation {
+		vc.remove(key)
+	}
+}
+
+// remove deletes key from the cache. The caller must hold vc.mu.
+func (vc *valueCache) remove(key string) {
+	entry, ok := vc.entries[key]
+	if !ok {
+		return
+	}
+
+	vc.recency.Remove(vc.elements[key])
+	vc.bytes -= entrySize(key, entry)
+
+	delete(vc.entries, key)
+	delete(vc.elements, key)
+}
+
+func entrySize(key string, entry *cacheEntry) int64 {
+	return int64(len(key) + len(entry.data))
+}
+
+// startRefresh reports whether the caller should refresh key in the background, which is the case unless
+// another refresh of it is in flight.
+func (vc *valueCache) startRefresh(key string) bool {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	if vc.refreshing[key] {
+		return false
+	}
+
+	vc.refreshing[key] = true
+
+	return true
+}
+
+func (vc *valueCache) endRefresh(key string) {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	delete(vc.refreshing, key)
+}
+
+// invalidate drops keys from the cache. It is a no-op on a client without a cache.
+func (vc *valueCache) invalidate(keys ...string) {
+	if vc == nil {
+		return
+	}
+
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	for _, key := range keys {
+		vc.remove(key)
+	}
+
+	vc.generation++
+}
@@ -0,0 +1,51 @@
+package raccoon_kv_client
+
+// CacheStats is a snapshot of the activity of a client's cache.
+type CacheStats struct {
+	// Hits counts Gets answered from the cache, including values the server confirmed unchanged without sending them
+	// again. Misses counts Gets that had to download the value or learn from the server that the key is missing.
+	Hits   int64
+	Misses int64
+	// Evictions counts keys dropped to keep the cache within the limits set by WithCacheLimits.
+	Evictions int64
+	// Entries and Bytes are the current size of the cache, Bytes counting keys and values.
+	Entries int
+	Bytes   int64
+}
+
+// WithCacheLimits enables the cache and bounds it to maxEntries keys and maxBytes bytes of keys and values,
+// evicting the least recently used keys to stay within them. A limit that is zero or negative is not enforced.
+func WithCacheLimits(maxEntries int, maxBytes int64) Option {
+	return func(c *Client) {
+		c.initCache()
+		c.cache.maxEntries = maxEntries
+		c.cache.maxBytes = maxBytes
+	}
+}
+
+// CacheStats returns a snapshot of the activity of the client's cache, or zero stats for a client without one.
+func (c *Client) CacheStats() CacheStats {
+	if c.cache == nil {
+		return CacheStats{}
+	}
+
+	c.cache.mu.Lock()
+	defer c.cache.mu.Unlock()
+
+	stats := c.cache.stats
+	stats.Entries = len(c.cache.entries)
+	stats.Bytes = c.cache.bytes
+
+	return stats
+}
+
+func (vc *valueCache) count(hit bool) {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	if hit {
+		vc.stats.Hits++
+	} else {
+		vc.stats.Misses++
+	}
+}
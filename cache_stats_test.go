@@ -0,0 +1,114 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestCacheLimits(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxEntries int
+		maxBytes   int64
+		gets       []string
+		want       []string
+		evictions  int64
+	}{
+		{"within limits", 3, 0, []string{"a", "b", "c"}, []string{"a", "b", "c"}, 0},
+		{"entries", 2, 0, []string{"a", "b", "c"}, []string{"b", "c"}, 1},
+		{"least recently used", 2, 0, []string{"a", "b", "a", "c"}, []string{"a", "c"}, 1},
+		{"bytes", 0, 20, []string{"a", "b", "c"}, []string{"b", "c"}, 1},
+		{"either limit", 1, 1000, []string{"a", "b"}, []string{"b"}, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newCacheServer()
+			for _, key := range []string{"a", "b", "c"} {
+				server.set(key, "value "+key)
+			}
+
+			c := cachedClient(t, server, WithCacheLimits(test.maxEntries, test.maxBytes))
+
+			for _, key := range test.gets {
+				getValue(t, c, key)
+			}
+
+			for _, key := range []string{"a", "b", "c"} {
+				_, cached := c.cache.lookup(key)
+				if want := slices.Contains(test.want, key); cached != want {
+					t.Errorf("%q cached = %t, want %t", key, cached, want)
+				}
+			}
+
+			// Every entry holds a one-byte key and a seven-byte value.
+			stats := c.CacheStats()
+			if stats.Evictions != test.evictions {
+				t.Errorf("Evictions = %d, want %d", stats.Evictions, test.evictions)
+			}
+
+			if want := len(test.want); stats.Entries != want || stats.Bytes != int64(8*want) {
+				t.Errorf("Entries, Bytes = %d, %d, want %d, %d", stats.Entries, stats.Bytes, want, 8*want)
+			}
+		})
+	}
+}
+
+func TestCacheStats(t *testing.T) {
+	server := newCacheServer()
+	server.set("k", "value")
+
+	c := cachedClient(t, server)
+
+	getValue(t, c, "k")
+	getValue(t, c, "k")
+	_, _, _ = c.Get(context.Background(), "missing")
+
+	if stats := c.CacheStats(); stats.Hits != 1 || stats.Misses != 2 {
+		t.Errorf("Hits, Misses = %d, %d, want 1, 2", stats.Hits, stats.Misses)
+	}
+
+	if stats := (&Client{}).CacheStats(); stats != (CacheStats{}) {
+		t.Errorf("CacheStats without a cache = %+v, want zero", stats)
+	}
+}
+
+func TestCacheEvictionStopsWatch(t *testing.T) {
+	server := newWatchedServer()
+	close(server.open)
+
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	c, err := NewClient(ts.URL, WithCacheWatches(), WithCacheLimits(1, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	watching := func(key string) bool {
+		watches := c.cache.currentWatches()
+
+		watches.mu.Lock()
+		defer watches.mu.Unlock()
+
+		_, ok := watches.watches[key]
+		return ok
+	}
+
+	if _, _, err := c.Get(context.Background(), "a"); err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, "the watch of a to start", func() bool { return watching("a") })
+
+	if _, _, err := c.Get(context.Background(), "b"); err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, "the watch of b to start", func() bool { return watching("b") })
+
+	if watching("a") {
+		t.Error("a is still watched after its eviction")
+	}
+}
@@ -0,0 +1,295 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// cacheServer stores versioned values and answers conditional Gets with 304 Not Modified. Gets wait for hold, if
+// it is set, after reading the value they answer with.
+type cacheServer struct {
+	mu         sync.Mutex
+	values     map[string]string
+	versions   map[string]int
+	version    int
+	gets       int
+	downloads  int
+	conditions []string
+	hold       chan struct{}
+}
+
+func newCacheServer() *cacheServer {
+	return &cacheServer{values: map[string]string{}, versions: map[string]int{}}
+}
+
+func (s *cacheServer) set(key string, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values[key] = value
+	s.version++
+	s.versions[key] = s.version
+}
+
+func (s *cacheServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, "/kv/")
+
+	switch r.Method {
+	case "PUT":
+		data, _ := io.ReadAll(r.Body)
+		s.set(key, string(data))
+		w.WriteHeader(http.StatusNoContent)
+		return
+	case "DELETE":
+		s.mu.Lock()
+		delete(s.values, key)
+		s.version++
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	s.mu.Lock()
+	s.gets++
+	s.conditions = append(s.conditions, r.Header.Get("if-none-match"))
+	value, ok := s.values[key]
+	etag := fmt.Sprint(s.versions[key])
+	if !ok {
+		etag = fmt.Sprint(s.version)
+	}
+	hold := s.hold
+	s.mu.Unlock()
+
+	if hold != nil {
+		<-hold
+	}
+
+	w.Header().Set("etag", etag)
+
+	switch {
+	case !ok:
+		w.WriteHeader(http.StatusNotFound)
+	case r.Header.Get("if-none-match") == etag:
+		w.WriteHeader(http.StatusNotModified)
+	default:
+		s.mu.Lock()
+		s.downloads++
+		s.mu.Unlock()
+
+		_, _ = w.Write([]byte(value))
+	}
+}
+
+// counts returns the number of Gets and of values downloaded so far.
+func (s *cacheServer) counts() (gets int, downloads int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.gets, s.downloads
+}
+
+func cachedClient(t *testing.T, server *cacheServer, opts ...Option) *Client {
+	t.Helper()
+
+	ts := httptest.NewServer(server)
+	t.Cleanup(ts.Close)
+
+	c, err := NewClient(ts.URL, append([]Option{WithCache()}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+func getValue(t *testing.T, c *Client, key string) string {
+	t.Helper()
+
+	data, _, err := c.Get(context.Background(), key)
+	if err != nil {
+		t.Fatalf("Get(%q) = %v", key, err)
+	}
+
+	return string(data)
+}
+
+func TestCacheRevalidation(t *testing.T) {
+	server := newCacheServer()
+	server.set("k", "v1")
+
+	c := cachedClient(t, server)
+
+	for range 3 {
+		if got := getValue(t, c, "k"); got != "v1" {
+			t.Fatalf("Get = %q, want %q", got, "v1")
+		}
+	}
+
+	if gets, downloads := server.counts(); gets != 3 || downloads != 1 {
+		t.Errorf("gets = %d, downloads = %d, want every Get revalidated and the value downloaded once", gets, downloads)
+	}
+
+	if conditions := server.conditions; conditions[0] != "" || conditions[1] != "1" || conditions[2] != "1" {
+		t.Errorf("If-None-Match headers = %q, want none and then the cached version", conditions)
+	}
+
+	server.set("k", "v2")
+
+	if got := getValue(t, c, "k"); got != "v2" {
+		t.Errorf("Get after a change = %q, want %q", got, "v2")
+	}
+
+	if stats := c.CacheStats(); stats.Hits != 2 || stats.Misses != 2 || stats.Entries != 1 {
+		t.Errorf("stats = %+v, want 2 hits, 2 misses and 1 entry", stats)
+	}
+}
+
+func TestCacheWriteRacingRead(t *testing.T) {
+	server := newCacheServer()
+	server.set("k", "v1")
+
+	hold := make(chan struct{})
+	server.hold = hold
+
+	c := cachedClient(t, server)
+
+	read := make(chan string)
+
+	go func() {
+		data, _, _ := c.Get(context.Background(), "k")
+		read <- string(data)
+	}()
+
+	eventually(t, "the Get to reach the server", func() bool {
+		gets, _ := server.counts()
+		return gets == 1
+	})
+
+	server.mu.Lock()
+	server.hold = nil
+	server.mu.Unlock()
+
+	// The write lands while the Get is in flight, so the value the Get then receives is already outdated and must
+	// not be cached.
+	if err := c.Put(context.Background(), "k", []byte("v2")); err != nil {
+		t.Fatal(err)
+	}
+
+	close(hold)
+
+	if got := <-read; got != "v1" {
+		t.Fatalf("racing Get = %q, want %q", got, "v1")
+	}
+
+	if entry, cached := c.cache.lookup("k"); cached {
+		t.Errorf("cached %q after the racing write, want nothing cached", entry.data)
+	}
+
+	if got := getValue(t, c, "k"); got != "v2" {
+		t.Errorf("Get after the racing write = %q, want %q", got, "v2")
+	}
+}
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	server := newCacheServer()
+	server.set("k", "v1")
+
+	c := cachedClient(t, server, WithStaleWhileRevalidate(time.Minute))
+
+	getValue(t, c, "k")
+
+	server.set("k", "v2")
+
+	hold := make(chan struct{})
+
+	server.mu.Lock()
+	server.hold = hold
+	server.mu.Unlock()
+
+	// While the refresh is held up, every Get returns the stale value at once and no second refresh starts.
+	for range 3 {
+		if got := getValue(t, c, "k"); got != "v1" {
+			t.Fatalf("Get during the refresh = %q, want the stale %q", got, "v1")
+		}
+	}
+
+	eventually(t, "the refresh to reach the server", func() bool {
+		gets, _ := server.counts()
+		return gets >= 2
+	})
+
+	time.Sleep(20 * time.Millisecond)
+
+	if gets, _ := server.counts(); gets != 2 {
+		t.Errorf("gets = %d, want a single refresh in flight", gets)
+	}
+
+	server.mu.Lock()
+	server.hold = nil
+	server.mu.Unlock()
+
+	close(hold)
+
+	eventually(t, "the refreshed value", func() bool { return getValue(t, c, "k") == "v2" })
+}
+
+func TestStaleWhileRevalidateExpired(t *testing.T) {
+	server := newCacheServer()
+	server.set("k", "v1")
+
+	c := cachedClient(t, server, WithStaleWhileRevalidate(time.Millisecond))
+
+	getValue(t, c, "k")
+	server.set("k", "v2")
+	time.Sleep(5 * time.Millisecond)
+
+	if got := getValue(t, c, "k"); got != "v2" {
+		t.Errorf("Get of a value older than maxStale = %q, want it revalidated to %q", got, "v2")
+	}
+}
+
+func TestNegativeCache(t *testing.T) {
+	server := newCacheServer()
+
+	c := cachedClient(t, server, WithNegativeCache(50*time.Millisecond))
+
+	missing := func() {
+		t.Helper()
+
+		if _, _, err := c.Get(context.Background(), "k"); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("Get = %v, want ErrNotFound", err)
+		}
+	}
+
+	missing()
+	missing()
+
+	if gets, _ := server.counts(); gets != 1 {
+		t.Errorf("gets = %d, want the second Get answered from the cache", gets)
+	}
+
+	// Once the ttl has passed, the server is asked again.
+	time.Sleep(60 * time.Millisecond)
+	missing()
+
+	if gets, _ := server.counts(); gets != 2 {
+		t.Errorf("gets = %d, want the key checked again after the ttl", gets)
+	}
+
+	// Writing the key through the client forgets that it was missing.
+	if err := c.Put(context.Background(), "k", []byte("v1")); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := getValue(t, c, "k"); got != "v1" {
+		t.Errorf("Get after a write = %q, want %q", got, "v1")
+	}
+}
@@ -0,0 +1,186 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// cacheWatches keeps a watch running on every key in the cache.
+type cacheWatches struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	opts   []WatchOption
+
+	mu      sync.Mutex
+	watches map[string]*cacheWatch
+}
+
+type cacheWatch struct {
+	cancel context.CancelFunc
+	stats  *watchStats
+}
+
+// WithCacheWatches enables the cache and watches every key in it for as long as the client is open, from the moment
+// the key is first cached until it is evicted or Close is called. Changes update the cached value as soon as the
+// server reports them, and while the watch of a key is connected Get returns its cached value without asking the
+// server. A watch counts as connected once a poll has succeeded and until one fails. opts configure the watches.
+func WithCacheWatches(opts ...WatchOption) Option {
+	return func(c *Client) {
+		c.initCache()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		c.cache.startWatches(ctx, cancel, opts)
+	}
+}
+
+// StartCacheWatches is WithCacheWatches for a client that is already open, watching the cached keys until ctx ends
+// instead of until Close. Calling it, or configuring WithCacheWatches, replaces the watches started before. It is a
+// no-op on a client without a cache.
+func (c *Client) StartCacheWatches(ctx context.Context, opts ...WatchOption) {
+	if c.cache == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	for _, key := range c.cache.startWatches(ctx, cancel, opts) {
+		c.watchCached(key)
+	}
+}
+
+// Close stops the watches of WithCacheWatches and StartCacheWatches and closes idle connections. The client can
+// still be used afterwards, but cached keys are no longer watched.
+func (c *Client) Close() {
+	if c.cache != nil {
+		if watches := c.cache.currentWatches(); watches != nil {
+			watches.cancel()
+		}
+	}
+
+	c.CloseIdleConnections()
+}
+
+// startWatches replaces the cache's watches with a set that runs until ctx ends, and returns the keys cached so
+// far, which the new set has yet to watch.
+func (vc *valueCache) startWatches(ctx context.Context, cancel context.CancelFunc, opts []WatchOption) []string {
+	watches := &cacheWatches{ctx: ctx, cancel: cancel, opts: opts, watches: map[string]*cacheWatch{}}
+
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	if vc.watches != nil {
+		vc.watches.cancel()
+	}
+
+	vc.watches = watches
+
+	keys := make([]string, 0, len(vc.entries))
+	for key := range vc.entries {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// watchCached starts watching key if it is cached and not watched yet.
+func (c *Client) watchCached(key string) {
+	if c.cache == nil {
+		return
+	}
+
+	watches := c.cache.currentWatches()
+	if watches == nil || watches.ctx.Err() != nil {
+		return
+	}
+
+	entry, ok := c.cache.lookup(key)
+	if !ok {
+		return
+	}
+
+	watches.mu.Lock()
+	defer watches.mu.Unlock()
+
+	if _, ok := watches.watches[key]; ok {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(watches.ctx)
+
+	config := c.newWatchConfig(append(slices.Clone(watches.opts), WithStartVersion(entry.version)))
+
+	watch := &cacheWatch{cancel: cancel, stats: config.stats}
+	watches.watches[key] = watch
+
+	go func() {
+		defer cancel()
+
+		_ = c.watchEvents(ctx, key, c.watchUrl(key), config, func(event Event) {
+			c.cache.apply(event)
+		})
+
+		watches.mu.Lock()
+		defer watches.mu.Unlock()
+
+		if watches.watches[key] == watch {
+			delete(watches.watches, key)
+		}
+	}()
+}
+
+// unwatch stops watching key, if it is watched.
+func (w *cacheWatches) unwatch(key string) {
+	if w == nil {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if watch, ok := w.watches[key]; ok {
+		watch.cancel()
+		delete(w.watches, key)
+	}
+}
+
+// connected reports whether key is watched by a watch that has heard from the server and has not failed since.
+func (w *cacheWatches) connected(key string) bool {
+	if w == nil {
+		return false
+	}
+
+	w.mu.Lock()
+	watch, ok := w.watches[key]
+	w.mu.Unlock()
+
+	if !ok {
+		return false
+	}
+
+	stats := watch.stats.snapshot()
+
+	return !stats.LastSuccess.IsZero() && stats.LastSuccess.After(stats.LastErrorAt)
+}
+
+func (vc *valueCache) currentWatches() *cacheWatches {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	return vc.watches
+}
+
+// apply updates the cache with a change reported by a watch.
+func (vc *valueCache) apply(event Event) {
+	generation := vc.currentGeneration()
+
+	switch {
+	case event.Type == EventDelete && vc.negativeTTL > 0:
+		vc.store(event.Key, &cacheEntry{version: event.Version, validatedAt: time.Now(), missing: true}, generation)
+	case event.Type == EventDelete:
+		vc.drop(event.Key, generation)
+	default:
+		vc.store(event.Key, &cacheEntry{data: slices.Clone(event.Value), version: event.Version, validatedAt: time.Now()}, generation)
+	}
+}
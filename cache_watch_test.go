@@ -0,0 +1,141 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// watchedServer serves a single value. Plain reads are counted; watch polls are held until the server is opened
+// and then long-poll the value for up to 50ms.
+type watchedServer struct {
+	reads atomic.Int32
+	polls atomic.Int32
+	open  chan struct{}
+
+	mu      sync.Mutex
+	value   string
+	version int
+	changed chan struct{}
+}
+
+func newWatchedServer() *watchedServer {
+	return &watchedServer{open: make(chan struct{}), value: "v1", version: 1, changed: make(chan struct{})}
+}
+
+func (s *watchedServer) set(value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.value = value
+	s.version++
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+func (s *watchedServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Has("watch") {
+		s.polls.Add(1)
+
+		select {
+		case <-s.open:
+		case <-r.Context().Done():
+			return
+		}
+	} else {
+		s.reads.Add(1)
+	}
+
+	s.mu.Lock()
+	etag := fmt.Sprint(s.version)
+	changed := s.changed
+	s.mu.Unlock()
+
+	if r.Header.Get("if-none-match") == etag && r.URL.Query().Has("watch") {
+		select {
+		case <-changed:
+		case <-time.After(50 * time.Millisecond):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	etag = fmt.Sprint(s.version)
+	w.Header().Set("etag", etag)
+
+	if r.Header.Get("if-none-match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	_, _ = w.Write([]byte(s.value))
+}
+
+// eventually polls condition until it holds or a second has passed.
+func eventually(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+
+	for deadline := time.Now().Add(time.Second); !condition(); {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestWithCacheWatches(t *testing.T) {
+	server := newWatchedServer()
+
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	c, err := NewClient(ts.URL, WithCacheWatches())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	get := func() string {
+		data, _, err := c.Get(context.Background(), "k")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return string(data)
+	}
+
+	get()
+	eventually(t, "the watch to start", func() bool { return server.polls.Load() > 0 })
+
+	// Until its first poll succeeds, the watch does not count as connected and Get revalidates with the server.
+	get()
+	if reads := server.reads.Load(); reads != 2 {
+		t.Errorf("reads before the first poll = %d, want 2", reads)
+	}
+
+	close(server.open)
+	eventually(t, "the watch to connect", func() bool { return c.cache.currentWatches().connected("k") })
+
+	if got := get(); got != "v1" || server.reads.Load() != 2 {
+		t.Errorf("Get = %q after %d reads, want %q from the cache", got, server.reads.Load(), "v1")
+	}
+
+	server.set("v2")
+	eventually(t, "the change to reach the cache", func() bool { return get() == "v2" })
+
+	if reads := server.reads.Load(); reads != 2 {
+		t.Errorf("reads = %d, want the change to be delivered by the watch", reads)
+	}
+
+	c.Close()
+	eventually(t, "the watch to stop", func() bool { return !c.cache.currentWatches().connected("k") })
+}
@@ -0,0 +1,67 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+type callConfig struct {
+	timeout time.Duration
+	header  http.Header
+
+	idempotencyKey string
+	annotation     string
+	resumes        int
+}
+
+// CallOption configures a single operation.
+type CallOption func(*callConfig)
+
+type callConfigKey struct{}
+
+// Timeout overrides the client's read or write timeout for one operation.
+func Timeout(timeout time.Duration) CallOption {
+	return func(config *callConfig) {
+		config.timeout = timeout
+	}
+}
+
+// Header adds a header to the requests of one operation, overriding a client-wide header of the same name.
+func Header(name string, value string) CallOption {
+	return func(config *callConfig) {
+		if config.header == nil {
+			config.header = http.Header{}
+		}
+
+		config.header.Add(name, value)
+	}
+}
+
+// ContextWithCallOptions returns a context that applies opts to any operation it is passed to, for operations that
+// have no WithOptions variant. Options from an enclosing context are kept unless overridden.
+func ContextWithCallOptions(ctx context.Context, opts ...CallOption) context.Context {
+	config := callOptions(ctx)
+	config.header = config.header.Clone()
+
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	return context.WithValue(ctx, callConfigKey{}, config)
+}
+
+func callOptions(ctx context.Context) callConfig {
+	config, _ := ctx.Value(callConfigKey{}).(callConfig)
+	return config
+}
+
+// GetWithOptions is Get with per-call options.
+func (c *Client) GetWithOptions(ctx context.Context, key string, opts ...CallOption) (data []byte, version string, err error) {
+	return c.Get(ContextWithCallOptions(ctx, opts...), key)
+}
+
+// PutWithOptions is Put with per-call options.
+func (c *Client) PutWithOptions(ctx context.Context, key string, data []byte, opts ...CallOption) error {
+	return c.Put(ContextWithCallOptions(ctx, opts...), key, data)
+}
@@ -0,0 +1,114 @@
+package raccoon_kv_client
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// checksumHeader carries the hex-encoded SHA-256 of a value as stored by the server.
+const checksumHeader = "x-raccoon-checksum-sha256"
+
+// ErrChecksumMismatch is returned when a value read does not match the checksum the server reports for it.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// ChecksumPolicy decides what happens when a value read does not match its checksum.
+type ChecksumPolicy int
+
+const (
+	// ChecksumReject fails the read with ErrChecksumMismatch.
+	ChecksumReject ChecksumPolicy = iota
+	// ChecksumWarn logs a warning and returns the value anyway.
+	ChecksumWarn
+)
+
+type checksums struct {
+	policy ChecksumPolicy
+}
+
+// WithChecksums sends the SHA-256 of every value written by Put, for the server to verify and store, and checks
+// values read by Get against the checksum the server reports, handling a mismatch according to policy. Values
+// delivered by event-stream and WebSocket watches carry no checksum and are not checked.
+func WithChecksums(policy ChecksumPolicy) Option {
+	return func(c *Client) {
+		c.checksums = &checksums{policy: policy}
+	}
+}
+
+// addChecksum adds the checksum of data to header if the client sends checksums.
+func (c *Client) addChecksum(data []byte, header http.Header) http.Header {
+	if c.checksums == nil {
+		return header
+	}
+
+	sum := sha256.Sum256(data)
+
+	header = header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+
+	header.Set(checksumHeader, hex.EncodeToString(sum[:]))
+
+	return header
+}
+
+// expectedChecksum returns the checksum the server reports for the value in response, or an empty string if there
+// is none to check. Values passed through compressed cannot be checked.
+func (c *Client) expectedChecksum(response *http.Response) string {
+	if c.checksums == nil {
+		return ""
+	}
+
+	if c.rawValues && strings.EqualFold(response.Header.Get("content-encoding"), "gzip") {
+		return ""
+	}
+
+	return response.Header.Get(checksumHeader)
+}
+
+// compareChecksum handles a mismatch between the checksum reported in response and sum according to the
+// client's policy.
+func (c *Client) compareChecksum(response *http.Response, sum []byte) error {
+	expected := c.expectedChecksum(response)
+
+	if actual := hex.EncodeToString(sum); actual != expected {
+		err := fmt.Errorf("%w: %s reports %s, value has %s", ErrChecksumMismatch, response.Request.URL.Redacted(), expected, actual)
+
+		if c.checksums.policy == ChecksumWarn {
+			c.log().Warn("value does not match its checksum", slog.String("err", err.Error()), responseRequestIDAttr(response))
+			return nil
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// checksumReader hashes a value as it is read and checks it against its checksum once it has been read fully.
+type checksumReader struct {
+	io.Reader
+	client   *Client
+	response *http.Response
+	hash     hash.Hash
+}
+
+func (r *checksumReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.hash.Write(p[:n])
+
+	if err == io.EOF {
+		if mismatch := r.client.compareChecksum(r.response, r.hash.Sum(nil)); mismatch != nil {
+			return n, mismatch
+		}
+	}
+
+	return n, err
+}
@@ -0,0 +1,153 @@
+package raccoon_kv_client
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without contacting the server while the client's circuit breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// CircuitState is the state of a CircuitBreaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets all requests through.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails all requests fast with ErrCircuitOpen.
+	CircuitOpen
+	// CircuitHalfOpen lets a single probe request through to find out whether the server has recovered.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreaker stops sending requests to a struggling server. After FailureThreshold consecutive failures,
+// network errors or 5xx responses, it opens and fails requests fast for OpenDuration. It then lets one probe
+// request through, closing again if the probe succeeds and reopening if it fails.
+type CircuitBreaker struct {
+	// FailureThreshold is the number of consecutive failures that opens the circuit. It defaults to five.
+	FailureThreshold int
+	// OpenDuration is how long the circuit stays open before probing. It defaults to thirty seconds.
+	OpenDuration time.Duration
+	// OnStateChange is called whenever the circuit changes state.
+	OnStateChange func(from CircuitState, to CircuitState)
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+}
+
+// WithCircuitBreaker guards every request of the client with breaker.
+func WithCircuitBreaker(breaker *CircuitBreaker) Option {
+	return func(c *Client) {
+		c.breaker = breaker
+	}
+}
+
+// State returns the current state of the circuit.
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state
+}
+
+// allow reports whether a request may be sent, moving an open circuit whose open duration has elapsed to
+// half-open and admitting the caller as its probe.
+func (b *CircuitBreaker) allow() bool {
+	b.mu.Lock()
+
+	from := b.state
+	allowed := false
+
+	switch b.state {
+	case CircuitClosed:
+		allowed = true
+	case CircuitOpen:
+		openDuration := b.OpenDuration
+		if openDuration <= 0 {
+			openDuration = 30 * time.Second
+		}
+
+		if time.Since(b.openedAt) >= openDuration {
+			b.state = CircuitHalfOpen
+			allowed = true
+		}
+	}
+
+	to := b.state
+	b.mu.Unlock()
+
+	b.notify(from, to)
+
+	return allowed
+}
+
+// record updates the circuit with the outcome of a request that allow admitted. A request cancelled by its
+// caller says nothing about the server; if it was the half-open probe, the next request probes instead.
+func (b *CircuitBreaker) record(response *http.Response, err error, cancelled bool) {
+	b.mu.Lock()
+
+	from := b.state
+
+	switch {
+	case cancelled:
+		if b.state == CircuitHalfOpen {
+			b.state = CircuitOpen
+			b.openedAt = time.Time{}
+		}
+	case err == nil && response.StatusCode < http.StatusInternalServerError:
+		b.failures = 0
+		b.state = CircuitClosed
+	default:
+		b.failures++
+
+		threshold := b.FailureThreshold
+		if threshold <= 0 {
+			threshold = 5
+		}
+
+		if b.state == CircuitHalfOpen || b.failures >= threshold {
+			b.state = CircuitOpen
+			b.openedAt = time.Now()
+		}
+	}
+
+	to := b.state
+	b.mu.Unlock()
+
+	b.notify(from, to)
+}
+
+func (b *CircuitBreaker) notify(from CircuitState, to CircuitState) {
+	if from != to && b.OnStateChange != nil {
+		b.OnStateChange(from, to)
+	}
+}
+
+// guard sends request through send unless the breaker is open.
+func (b *CircuitBreaker) guard(request *http.Request, send RoundTripFunc) (*http.Response, error) {
+	if !b.allow() {
+		return nil, ErrCircuitOpen
+	}
+
+	response, err := send(request)
+	b.record(response, err, request.Context().Err() != nil)
+
+	return response, err
+}
@@ -0,0 +1,108 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var failing atomic.Bool
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("etag", "1")
+		_, _ = w.Write([]byte("value"))
+	}))
+	defer server.Close()
+
+	var transitions []string
+
+	breaker := &CircuitBreaker{
+		FailureThreshold: 2,
+		OpenDuration:     50 * time.Millisecond,
+		OnStateChange: func(from CircuitState, to CircuitState) {
+			transitions = append(transitions, from.String()+">"+to.String())
+		},
+	}
+
+	c, err := NewClient(server.URL, WithCircuitBreaker(breaker))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	get := func() error {
+		_, _, err := c.Get(context.Background(), "k")
+		return err
+	}
+
+	failing.Store(true)
+
+	for range 2 {
+		if err := get(); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("Get = %v, want the server error", err)
+		}
+	}
+
+	if err := get(); !errors.Is(err, ErrCircuitOpen) || requests.Load() != 2 {
+		t.Fatalf("Get = %v after %d requests, want ErrCircuitOpen without a request", err, requests.Load())
+	}
+
+	// A failed probe reopens the circuit.
+	time.Sleep(60 * time.Millisecond)
+
+	if err := get(); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("probe = %v, want the server error", err)
+	}
+
+	if state := breaker.State(); state != CircuitOpen {
+		t.Fatalf("state after a failed probe = %s, want open", state)
+	}
+
+	// A successful probe closes it.
+	time.Sleep(60 * time.Millisecond)
+	failing.Store(false)
+
+	if err := get(); err != nil {
+		t.Fatalf("probe = %v, want nil", err)
+	}
+
+	want := []string{"closed>open", "open>half-open", "half-open>open", "open>half-open", "half-open>closed"}
+	if !slices.Equal(transitions, want) {
+		t.Errorf("transitions = %v, want %v", transitions, want)
+	}
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	breaker := &CircuitBreaker{FailureThreshold: 1, OpenDuration: 10 * time.Millisecond}
+
+	breaker.record(nil, errors.New("unreachable"), false)
+	time.Sleep(20 * time.Millisecond)
+
+	if !breaker.allow() {
+		t.Fatal("allow after the open duration = false, want the probe to be admitted")
+	}
+
+	if breaker.allow() {
+		t.Error("allow during the probe = true, want only one probe")
+	}
+
+	// A probe cancelled by its caller says nothing about the server, so the next request probes at once.
+	breaker.record(nil, context.Canceled, true)
+
+	if !breaker.allow() {
+		t.Error("allow after a cancelled probe = false, want a new probe")
+	}
+}
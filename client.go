@@ -3,81 +3,665 @@ package raccoon_kv_client
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"sync"
 	"time"
 )
 
 type Client struct {
 	Url string
+
+	// Backoff paces retries after failures. DefaultBackoff is used if it is nil.
+	Backoff Backoff
+
+	httpOnce     sync.Once
+	httpClient   *http.Client
+	roundTripper http.RoundTripper
+	tlsConfig    *tls.Config
+	proxy        *url.URL
+	unixSocket   string
+	endpoints    []*endpoint
+	balancer     Balancer
+	hedgeDelay   time.Duration
+	retryPolicy  *RetryPolicy
+	breaker      *CircuitBreaker
+	limiter      *rateLimiter
+	adaptive     *adaptiveTimeouts
+	logger       *slog.Logger
+	logLevel     *slog.Level
+	tracer       Tracer
+	metrics      MetricsCollector
+	hooks        Hooks
+	middleware   []Middleware
+	debug        *debugWriter
+	audit        func(AuditRecord)
+	compression  *compression
+	rawValues    bool
+	keys         KeyProvider
+	checksums    *checksums
+	maxValueSize int64
+	maxPutSize   int64
+	cache        *valueCache
+	flights      *flightGroup
+	persistent   *persistentCache
+	namespace    string
+
+	idempotencyKeys bool
+	bearerToken     string
+	basicAuth       *basicAuth
+	apiKey          *apiKey
+	credentials     *credentialsCache
+	signer          RequestSigner
+	header          http.Header
+
+	userAgentSuffix string
+
+	readTimeout       time.Duration
+	writeTimeout      time.Duration
+	watchPollDuration time.Duration
+
+	// err records an invalid option, reported by NewClient.
+	err error
+}
+
+// KeyVersion is a key together with its current version, as returned by List.
+type KeyVersion struct {
+	Key     string `json:"key"`
+	Version string `json:"version"`
+}
+
+type listPage struct {
+	Keys   []KeyVersion `json:"keys"`
+	Cursor string       `json:"cursor"`
 }
 
 var RequestFailedErr = errors.New("")
 
+var ErrNotFound = errors.New("key not found")
+
+// ErrConflict is returned by conditional operations when the key's version no longer matches the expected one.
+var ErrConflict = errors.New("version conflict")
+
+// ErrAlreadyExists is returned by Create when the key is already present.
+var ErrAlreadyExists = errors.New("key already exists")
+
+// Get returns the value of key and its version. It returns ErrNotFound if the key does not exist, together with
+// the version the server reports for the missing key so a watch can be started from it. An existing empty value
+// is returned as a non-nil empty slice.
 func (c *Client) Get(ctx context.Context, key string) (data []byte, version string, err error) {
-	return doRequest(ctx, fmt.Sprintf("%s/kv/%s", c.Url, key), "", time.Second*10)
+	keyUrl, err := c.keyUrl(key, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if c.flights != nil && shareable(ctx) {
+		return c.flights.do(ctx, keyUrl, func(ctx context.Context) ([]byte, string, error) {
+			return c.get(ctx, key, keyUrl)
+		})
+	}
+
+	return c.get(ctx, key, keyUrl)
+}
+
+func (c *Client) get(ctx context.Context, key string, keyUrl string) (data []byte, version string, err error) {
+	if c.cache != nil {
+		data, version, err = c.getCached(ctx, key, keyUrl)
+		c.watchCached(key)
+	} else {
+		data, version, err = c.doRequest(withHedging(ctx), key, keyUrl, "", c.readTimeoutFor(ctx))
+		if err == nil && data == nil {
+			err = ErrNotFound
+		}
+	}
+
+	c.persist(ctx, key, data, version, err)
+
+	return data, version, err
 }
 
-func (c *Client) Watch(ctx context.Context, key string, cb func([]byte)) {
-	var lastVersion string
+// Head reports whether key exists along with its version and size, without downloading the value.
+func (c *Client) Head(ctx context.Context, key string) (exists bool, version string, size int64, err error) {
+	keyUrl, err := c.keyUrl(key, nil)
+	if err != nil {
+		return false, "", 0, err
+	}
+
+	ctx, cancel := withTimeout(ctx, c.readTimeoutFor(ctx))
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, "HEAD", keyUrl, nil)
+	if err != nil {
+		return false, "", 0, err
+	}
+
+	response, err := c.doRead(request)
+	if err != nil {
+		return false, "", 0, err
+	}
+	defer drainAndClose(response.Body)
 
-	const duration = 60
+	switch response.StatusCode {
+	case http.StatusOK:
+		return true, response.Header.Get("etag"), response.ContentLength, nil
+	case http.StatusNotFound:
+		return false, response.Header.Get("etag"), 0, nil
+	default:
+		return false, "", 0, newResponseError(response)
+	}
+}
 
-	requestUrl := fmt.Sprintf("%s/kv/%s?watch=%d", c.Url, key, duration)
+// List returns all keys starting with prefix, following the server's pagination cursor until exhausted.
+func (c *Client) List(ctx context.Context, prefix string) ([]KeyVersion, error) {
+	var keys []KeyVersion
 
-	backoffSeconds := 1
+	query := url.Values{}
+	query.Set("prefix", c.serverKey(prefix))
 
 	for {
-		data, version, err := doRequest(ctx, requestUrl, lastVersion, time.Second*duration)
-		if err != nil {
-			if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
-				slog.Info("internal http client timeout, retrying")
-				continue
-			}
-
-			if ctx.Err() == nil {
-				slog.Error("failed to query kv store, backing off", slog.String("err", err.Error()), slog.Int("backoff_seconds", backoffSeconds))
-			}
-
-			select {
-			case <-ctx.Done():
-				slog.Info("context cancelled or deadline exceeded, stopping watch")
-				return
-			case <-time.NewTimer(time.Second * time.Duration(backoffSeconds)).C:
-			}
-
-			if backoffSeconds < 60 {
-				backoffSeconds = backoffSeconds * 2
-			}
-		} else if lastVersion != version {
-			lastVersion = version
-			cb(data)
+		var page listPage
+		if err := c.getJSON(ctx, fmt.Sprintf("%s/kv?%s", c.Url, query.Encode()), &page); err != nil {
+			return nil, err
 		}
+
+		for _, key := range page.Keys {
+			key.Key = c.clientKey(key.Key)
+			keys = append(keys, key)
+		}
+
+		if page.Cursor == "" {
+			return keys, nil
+		}
+
+		query.Set("cursor", page.Cursor)
 	}
 }
 
 func (c *Client) Put(ctx context.Context, key string, data []byte) error {
-	request, err := http.NewRequestWithContext(ctx, "PUT", fmt.Sprintf("%s/kv/%s", c.Url, key), bytes.NewReader(data))
+	_, err := c.put(ctx, key, nil, data, nil)
+	return err
+}
+
+// PutWithTTL writes data that the server expires after ttl, returning the expiration time the server applied.
+// The returned time is zero if the server does not report one.
+func (c *Client) PutWithTTL(ctx context.Context, key string, data []byte, ttl time.Duration) (expiresAt time.Time, err error) {
+	if ttl < time.Second {
+		return time.Time{}, fmt.Errorf("ttl must be at least one second, got %s", ttl)
+	}
+
+	query := url.Values{}
+	query.Set("ttl", fmt.Sprintf("%d", int64(ttl/time.Second)))
+
+	header, err := c.put(ctx, key, query, data, nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	expiresAt, err = http.ParseTime(header.Get("expires"))
+	if err != nil {
+		return time.Time{}, nil
+	}
+
+	return expiresAt, nil
+}
+
+// PutIfVersion writes data only if the key is currently at expectedVersion, returning ErrConflict otherwise.
+func (c *Client) PutIfVersion(ctx context.Context, key string, data []byte, expectedVersion string) error {
+	header := http.Header{}
+	header.Set("if-match", expectedVersion)
+
+	_, err := c.put(ctx, key, nil, data, header)
+	return err
+}
+
+// Create writes data only if the key does not exist yet, returning ErrAlreadyExists otherwise.
+func (c *Client) Create(ctx context.Context, key string, data []byte) error {
+	header := http.Header{}
+	header.Set("if-none-match", "*")
+
+	_, err := c.put(ctx, key, nil, data, header)
+	if errors.Is(err, ErrConflict) {
+		return ErrAlreadyExists
+	}
+
+	return err
+}
+
+// Copy duplicates the value of src into dst on the server, without transferring the value through the client.
+// Encrypted values are bound to their key, so with WithEncryption the value is read and written again instead. It
+// returns ErrNotFound if src does not exist.
+func (c *Client) Copy(ctx context.Context, src string, dst string) (err error) {
+	if err := ValidateKey(c.serverKey(src)); err != nil {
+		return err
+	}
+
+	if c.keys != nil {
+		data, _, err := c.Get(ctx, src)
+		if err != nil {
+			return err
+		}
+
+		return c.Put(ctx, dst, data)
+	}
+
+	dstUrl, err := c.keyUrl(dst, nil)
+	if err != nil {
+		return err
+	}
+
+	defer c.invalidate(dst)
+
+	var responseHeader http.Header
+
+	if c.audit != nil {
+		record := c.auditRecord(ctx, "put", dst, nil)
+
+		defer func() {
+			c.finishAudit(record, responseHeader, err)
+		}()
+	}
+
+	header := http.Header{}
+	header.Set("x-raccoon-copy-source", escapeKey(c.serverKey(src)))
+
+	response, err := c.doWrite(ctx, "PUT", dstUrl, nil, header)
+	if err != nil {
+		return err
+	}
+
+	responseHeader = response.Header
+
+	switch response.StatusCode {
+	case http.StatusNoContent:
+		return nil
+	case http.StatusNotFound:
+		return ErrNotFound
+	default:
+		return newResponseError(response)
+	}
+}
+
+// put writes data to key and returns the response headers on success.
+func (c *Client) put(ctx context.Context, key string, query url.Values, data []byte, header http.Header) (responseHeader http.Header, err error) {
+	requestUrl, err := c.keyUrl(key, query)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.checkPutSize(int64(len(data))); err != nil {
+		return nil, err
+	}
+
+	defer c.invalidate(key)
+
+	if c.audit != nil {
+		record := c.auditRecord(ctx, "put", key, header)
+		record.Size = len(data)
+
+		defer func() {
+			c.finishAudit(record, responseHeader, err)
+		}()
+	}
+
+	data, header, err = c.encrypt(ctx, key, data, header)
+	if err != nil {
+		return nil, err
+	}
+
+	header = c.addChecksum(data, header)
+
+	body, bodyHeader, compressed := c.compress(data, header)
+
+	response, err := c.doWrite(ctx, "PUT", requestUrl, bytes.NewReader(body), bodyHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	if compressed && response.StatusCode == http.StatusUnsupportedMediaType {
+		c.compression.unsupported.Store(true)
+
+		response, err = c.doWrite(ctx, "PUT", requestUrl, bytes.NewReader(data), header)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch response.StatusCode {
+	case http.StatusNoContent:
+		return response.Header, nil
+	case http.StatusPreconditionFailed:
+		return nil, ErrConflict
+	default:
+		return nil, newResponseError(response)
+	}
+}
+
+// Delete removes key from the store. It returns ErrNotFound if the key does not exist.
+func (c *Client) Delete(ctx context.Context, key string) error {
+	return c.delete(ctx, key, nil)
+}
+
+// DeleteIfVersion removes key only if it is currently at version, returning ErrConflict otherwise.
+func (c *Client) DeleteIfVersion(ctx context.Context, key string, version string) error {
+	header := http.Header{}
+	header.Set("if-match", version)
+
+	return c.delete(ctx, key, header)
+}
+
+func (c *Client) delete(ctx context.Context, key string, header http.Header) (err error) {
+	keyUrl, err := c.keyUrl(key, nil)
+	if err != nil {
+		return err
+	}
+
+	defer c.invalidate(key)
+
+	if c.audit != nil {
+		record := c.auditRecord(ctx, "delete", key, header)
+
+		defer func() {
+			c.finishAudit(record, nil, err)
+		}()
+	}
+
+	response, err := c.doWrite(ctx, "DELETE", keyUrl, nil, header)
+	if err != nil {
+		return err
+	}
+
+	switch response.StatusCode {
+	case http.StatusNoContent, http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusPreconditionFailed:
+		return ErrConflict
+	default:
+		return newResponseError(response)
+	}
+}
+
+func (c *Client) getJSON(ctx context.Context, url string, out any) error {
+	ctx, cancel := withTimeout(ctx, c.readTimeoutFor(ctx))
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return err
 	}
 
-	response, err := http.DefaultClient.Do(request)
+	response, err := c.doRead(request)
 	if err != nil {
 		return err
 	}
+	defer drainAndClose(response.Body)
+
+	if response.StatusCode != http.StatusOK {
+		return newResponseError(response)
+	}
+
+	return json.NewDecoder(response.Body).Decode(out)
+}
+
+func (c *Client) postJSON(ctx context.Context, url string, in any, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := withTimeout(ctx, c.writeTimeoutFor(ctx))
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("content-type", "application/json")
+	c.setIdempotencyKey(request)
+
+	response, err := c.do(request)
+	if err != nil {
+		return err
+	}
+	defer drainAndClose(response.Body)
+
+	if response.StatusCode != http.StatusOK {
+		return newResponseError(response)
+	}
+
+	return json.NewDecoder(response.Body).Decode(out)
+}
+
+// do sends request with the client's HTTP client under a request ID, tracing, measuring and hooking it and
+// retrying it according to the client's retry policy.
+func (c *Client) do(request *http.Request) (*http.Response, error) {
+	setRequestID(request)
+
+	send := c.doWithPolicy
+
+	if c.hooks.OnRequestStart != nil || c.hooks.OnRequestEnd != nil {
+		send = c.hook(send)
+	}
 
-	if response.StatusCode != http.StatusNoContent {
-		return fmt.Errorf("unexpected status code %d%w", response.StatusCode, RequestFailedErr)
+	if c.metrics != nil {
+		send = c.measure(send)
+	}
+
+	if c.tracer != nil {
+		send = c.trace(send)
+	}
+
+	response, err := send(request)
+	if err != nil {
+		return nil, &RequestError{RequestID: request.Header.Get("x-request-id"), Err: err}
+	}
+
+	return response, nil
+}
+
+// doRead is do for reads, giving up if the server has not responded within the adaptive read timeout, if any. The
+// transfer of the body is only bounded by the request's context.
+func (c *Client) doRead(request *http.Request) (*http.Response, error) {
+	timeout := c.headerTimeoutFor(request.Context())
+	if timeout <= 0 {
+		return c.do(request)
+	}
+
+	ctx, cancel := context.WithCancel(request.Context())
+	timer := time.AfterFunc(timeout, cancel)
+
+	response, err := c.do(request.WithContext(ctx))
+	if !timer.Stop() {
+		if err == nil {
+			drainAndClose(response.Body)
+		}
+
+		return nil, context.DeadlineExceeded
+	}
+
+	return response, err
+}
+
+func (c *Client) doWithPolicy(request *http.Request) (*http.Response, error) {
+	if c.retryPolicy != nil && !retriesDisabled(request.Context()) {
+		return c.doWithRetries(request)
+	}
+
+	return c.attempt(request)
+}
+
+// attempt sends request once through the client's middleware.
+func (c *Client) attempt(request *http.Request) (*http.Response, error) {
+	send := RoundTripFunc(c.dispatch)
+
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		send = c.middleware[i](send)
+	}
+
+	return send(request)
+}
+
+// dispatch sends request once, waiting for the rate limit, failing over to other endpoints if any are configured
+// and failing fast while the circuit breaker is open.
+func (c *Client) dispatch(request *http.Request) (*http.Response, error) {
+	if c.limiter != nil {
+		if err := c.limiter.wait(request.Context()); err != nil {
+			return nil, err
+		}
+	}
+
+	start := time.Now()
+
+	var response *http.Response
+	var err error
+
+	if c.breaker != nil {
+		response, err = c.breaker.guard(request, c.route)
+	} else {
+		response, err = c.route(request)
+	}
+
+	if c.adaptive != nil {
+		c.adaptive.observe(request, response, err, time.Since(start))
+	}
+
+	return response, err
+}
+
+// route sends request to the server it is addressed to, or through the configured endpoints.
+func (c *Client) route(request *http.Request) (*http.Response, error) {
+	if len(c.endpoints) > 1 {
+		return c.failover(request)
+	}
+
+	return c.send(request)
+}
+
+// send sends request to the server it is addressed to, retrying once with fresh credentials if it is rejected
+// with 401 Unauthorized.
+func (c *Client) send(request *http.Request) (*http.Response, error) {
+	if err := c.prepare(request); err != nil {
+		return nil, err
+	}
+
+	response, err := c.roundTrip(request)
+	if err != nil || response.StatusCode != http.StatusUnauthorized {
+		return response, err
+	}
+
+	retry, err := c.reauthorize(request)
+	if err != nil {
+		_ = response.Body.Close()
+		return nil, err
+	}
+
+	if retry == nil {
+		return response, nil
+	}
+
+	drainAndClose(response.Body)
+
+	return c.roundTrip(retry)
+}
+
+// prepare sets the User-Agent, deadline and trace context headers, adds the client's and the call's extra headers,
+// attaches credentials and signs request.
+func (c *Client) prepare(request *http.Request) error {
+	request.Header.Set("user-agent", c.userAgent())
+	setDeadline(request)
+
+	for name, values := range c.header {
+		request.Header[name] = values
+	}
+
+	for name, values := range callOptions(request.Context()).header {
+		request.Header[name] = values
+	}
+
+	if c.tracer != nil {
+		c.tracer.Inject(request.Context(), request.Header)
+	}
+
+	if err := c.authorize(request); err != nil {
+		return err
+	}
+
+	if c.signer != nil {
+		return c.signer.Sign(request)
 	}
 
 	return nil
 }
 
-func doRequest(ctx context.Context, url string, lastKnownVersion string, timeout time.Duration) (data []byte, version string, err error) {
+// withTimeout bounds ctx by timeout, leaving it unbounded if timeout is zero.
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+// doWrite sends a mutating request and closes the response body, leaving the status and headers for the caller.
+// The start of an error response's body is kept for newResponseError.
+func (c *Client) doWrite(ctx context.Context, method string, url string, body io.Reader, header http.Header) (*http.Response, error) {
+	ctx, cancel := withTimeout(ctx, c.writeTimeoutFor(ctx))
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, values := range header {
+		request.Header[name] = values
+	}
+
+	c.setIdempotencyKey(request)
+
+	return c.sendWrite(request)
+}
+
+// sendWrite sends a mutating request like doWrite.
+func (c *Client) sendWrite(request *http.Request) (*http.Response, error) {
+	response, err := c.do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode >= http.StatusBadRequest {
+		snippet, _ := io.ReadAll(io.LimitReader(response.Body, maxErrorBody))
+		drainAndClose(response.Body)
+
+		response.Body = io.NopCloser(bytes.NewReader(snippet))
+
+		return response, nil
+	}
+
+	drainAndClose(response.Body)
+
+	return response, nil
+}
+
+// doRequest fetches the value of key from url, returning nil data along with the version the server reports if the
+// key is missing, which lets watches start from that version.
+func (c *Client) doRequest(ctx context.Context, key string, url string, lastKnownVersion string, timeout time.Duration) (data []byte, version string, err error) {
+	return c.fetch(ctx, url, lastKnownVersion, timeout, c.valueOf(key))
+}
+
+// fetch is doRequest with the body of a 200 response read by read, for responses whose body is not a value.
+func (c *Client) fetch(ctx context.Context, url string, lastKnownVersion string, timeout time.Duration, read func(context.Context, *http.Response) ([]byte, error)) (data []byte, version string, err error) {
+	ctx, cancel := withTimeout(ctx, timeout)
+	defer cancel()
+
 	request, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, "", err
@@ -87,14 +671,19 @@ func doRequest(ctx context.Context, url string, lastKnownVersion string, timeout
 		request.Header.Set("if-none-match", lastKnownVersion)
 	}
 
-	client := http.Client{
-		Timeout: timeout,
-	}
+	c.acceptEncoding(request)
 
-	response, err := client.Do(request)
+	response, err := c.doRead(request)
 	if err != nil {
 		return nil, "", err
 	}
+	defer drainAndClose(response.Body)
+
+	switch response.StatusCode {
+	case http.StatusOK, http.StatusNotFound, http.StatusNotModified:
+	default:
+		return nil, "", newResponseError(response)
+	}
 
 	version = response.Header.Get("etag")
 	if version == "" {
@@ -109,14 +698,15 @@ func doRequest(ctx context.Context, url string, lastKnownVersion string, timeout
 		return nil, lastKnownVersion, nil
 	}
 
-	if response.StatusCode != http.StatusOK {
-		return nil, "", fmt.Errorf("unexpected status code %d%w", response.StatusCode, RequestFailedErr)
-	}
-
-	data, err = io.ReadAll(response.Body)
+	data, err = read(ctx, response)
 	if err != nil {
 		return nil, "", err
 	}
 
 	return data, version, nil
 }
+
+// skipBody reads nothing from a response whose body is not needed, returning empty data.
+func skipBody(context.Context, *http.Response) ([]byte, error) {
+	return []byte{}, nil
+}
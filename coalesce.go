@@ -0,0 +1,70 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// flightGroup lets concurrent identical reads share a single request.
+type flightGroup struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+// flight is a read in progress, whose result is available once done is closed.
+type flight struct {
+	done    chan struct{}
+	data    []byte
+	version string
+	err     error
+}
+
+// WithRequestCoalescing makes concurrent Gets of the same key share one request and its result, instead of each
+// sending its own. Gets with per-call options, or with retries disabled, always send their own request, since
+// their request would differ from the shared one. The shared request is not cancelled if the Get that started it
+// gives up; every Get still returns as soon as its own ctx ends.
+func WithRequestCoalescing() Option {
+	return func(c *Client) {
+		c.flights = &flightGroup{flights: map[string]*flight{}}
+	}
+}
+
+// shareable reports whether a read with ctx may be served by, or serve, another caller's request: it must not
+// carry per-call options or disable retries.
+func shareable(ctx context.Context) bool {
+	config := callOptions(ctx)
+	return config.timeout == 0 && len(config.header) == 0 && config.idempotencyKey == "" && config.annotation == "" &&
+		config.resumes == 0 && !retriesDisabled(ctx)
+}
+
+// do calls fetch for key unless a call for key is already in flight, and returns the result of whichever call
+// serves it.
+func (g *flightGroup) do(ctx context.Context, key string, fetch func(ctx context.Context) ([]byte, string, error)) ([]byte, string, error) {
+	g.mu.Lock()
+
+	f, ok := g.flights[key]
+	if !ok {
+		f = &flight{done: make(chan struct{})}
+		g.flights[key] = f
+
+		go func() {
+			f.data, f.version, f.err = fetch(context.WithoutCancel(ctx))
+
+			g.mu.Lock()
+			delete(g.flights, key)
+			g.mu.Unlock()
+
+			close(f.done)
+		}()
+	}
+
+	g.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return slices.Clone(f.data), f.version, f.err
+	case <-ctx.Done():
+		return nil, "", ctx.Err()
+	}
+}
@@ -0,0 +1,63 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRequestCoalescing(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []CallOption
+		requests int32
+	}{
+		{"shared", nil, 1},
+		{"per-call options", []CallOption{Header("x-tenant", "a")}, 3},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var requests atomic.Int32
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				time.Sleep(50 * time.Millisecond)
+
+				w.Header().Set("etag", "1")
+				_, _ = w.Write([]byte("value"))
+			}))
+			defer server.Close()
+
+			c, err := NewClient(server.URL, WithRequestCoalescing())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var wg sync.WaitGroup
+
+			for range 3 {
+				wg.Add(1)
+
+				go func() {
+					defer wg.Done()
+
+					data, _, err := c.GetWithOptions(context.Background(), "k", test.opts...)
+					if err != nil || string(data) != "value" {
+						t.Errorf("Get = %q, %v, want %q", data, err, "value")
+					}
+				}()
+			}
+
+			wg.Wait()
+
+			if got := requests.Load(); got != test.requests {
+				t.Errorf("requests = %d, want %d", got, test.requests)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"sync"
+)
+
+// ErrContentType is returned when a stored value's content type has no registered codec or does not match the
+// codec it is decoded with.
+var ErrContentType = errors.New("unexpected content type")
+
+// Codec encodes values to and from the bytes stored under a key.
+type Codec interface {
+	// ContentType is the media type of the encoded values, sent as their Content-Type.
+	ContentType() string
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// JSONCodec encodes values as JSON.
+var JSONCodec Codec = jsonCodec{}
+
+var codecs = struct {
+	sync.RWMutex
+	byType map[string]Codec
+}{
+	byType: map[string]Codec{},
+}
+
+func init() {
+	RegisterCodec(JSONCodec)
+}
+
+// RegisterCodec makes codec available for its content type to GetValue and PutValue, replacing a codec registered
+// earlier for the same type. JSON is registered by default; importing raccoonproto, raccoonmsgpack or raccooncbor
+// registers protocol buffers, MessagePack or CBOR.
+func RegisterCodec(codec Codec) {
+	codecs.Lock()
+	defer codecs.Unlock()
+
+	codecs.byType[mediaType(codec.ContentType())] = codec
+}
+
+// LookupCodec returns the codec registered for contentType, ignoring its parameters.
+func LookupCodec(contentType string) (Codec, bool) {
+	codecs.RLock()
+	defer codecs.RUnlock()
+
+	codec, ok := codecs.byType[mediaType(contentType)]
+	return codec, ok
+}
+
+// mediaType strips the parameters from contentType and lowercases it.
+func mediaType(contentType string) string {
+	if parsed, _, err := mime.ParseMediaType(contentType); err == nil {
+		return parsed
+	}
+
+	return contentType
+}
+
+// checkContentType verifies that a value stored with contentType can be decoded with codec. A value stored
+// without a content type is assumed to match.
+func checkContentType(codec Codec, contentType string) error {
+	if contentType == "" || mediaType(contentType) == mediaType(codec.ContentType()) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s, want %s", ErrContentType, contentType, codec.ContentType())
+}
+
+// GetValue fetches key and decodes its value into v with the codec registered for the content type it was
+// stored with. It returns an error wrapping ErrContentType if no codec is registered for it and one wrapping
+// ErrDecode if the value cannot be decoded.
+func (c *Client) GetValue(ctx context.Context, key string, v any) error {
+	entry, err := c.GetEntry(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	codec, ok := LookupCodec(entry.ContentType)
+	if !ok {
+		return fmt.Errorf("%w: %q has no registered codec", ErrContentType, entry.ContentType)
+	}
+
+	if err := codec.Unmarshal(entry.Value, v); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrDecode, key, err)
+	}
+
+	return nil
+}
+
+// PutValue encodes v with the codec registered for contentType and writes it to key with that content type.
+func (c *Client) PutValue(ctx context.Context, key string, v any, contentType string) error {
+	codec, ok := LookupCodec(contentType)
+	if !ok {
+		return fmt.Errorf("%w: %q has no registered codec", ErrContentType, contentType)
+	}
+
+	data, err := codec.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	header := http.Header{}
+	header.Set("content-type", codec.ContentType())
+
+	_, err = c.put(ctx, key, nil, data, header)
+	return err
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) ContentType() string {
+	return "application/json"
+}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,103 @@
+package raccoon_kv_client_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+
+	raccoon "github.com/RaccoonCorp/raccoon-kv-client"
+	"github.com/RaccoonCorp/raccoon-kv-client/raccooncbor"
+	"github.com/RaccoonCorp/raccoon-kv-client/raccoonmsgpack"
+	"github.com/RaccoonCorp/raccoon-kv-client/raccoonproto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// contentServer stores values together with the content type they were written with.
+func contentServer() *httptest.Server {
+	var mu sync.Mutex
+	values := map[string][]byte{}
+	contentTypes := map[string]string{}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if r.Method == "PUT" {
+			values[r.URL.Path], _ = io.ReadAll(r.Body)
+			contentTypes[r.URL.Path] = r.Header.Get("content-type")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		w.Header().Set("content-type", contentTypes[r.URL.Path])
+		w.Header().Set("etag", "1")
+		_, _ = w.Write(values[r.URL.Path])
+	}))
+}
+
+func TestRegisteredCodecs(t *testing.T) {
+	server := contentServer()
+	defer server.Close()
+
+	c, err := raccoon.NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, codec := range []raccoon.Codec{raccoon.JSONCodec, raccoonmsgpack.Codec, raccooncbor.Codec} {
+		t.Run(codec.ContentType(), func(t *testing.T) {
+			want := map[string]int{"a": 1}
+
+			if err := c.PutValue(context.Background(), "k", want, codec.ContentType()); err != nil {
+				t.Fatal(err)
+			}
+
+			var got map[string]int
+			if err := c.GetValue(context.Background(), "k", &got); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("GetValue = %v, want %v", got, want)
+			}
+		})
+	}
+
+	if err := c.PutValue(context.Background(), "k", "v", "application/yaml"); !errors.Is(err, raccoon.ErrContentType) {
+		t.Errorf("PutValue with an unregistered content type = %v, want ErrContentType", err)
+	}
+}
+
+func TestProtobufCodec(t *testing.T) {
+	server := contentServer()
+	defer server.Close()
+
+	c, err := raccoon.NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	typed := raccoon.Typed[*wrapperspb.StringValue](c, raccoonproto.Codec)
+
+	if err := typed.Put(context.Background(), "k", wrapperspb.String("value")); err != nil {
+		t.Fatal(err)
+	}
+
+	got, _, err := typed.Get(context.Background(), "k")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.GetValue() != "value" {
+		t.Errorf("Get = %q, want %q", got.GetValue(), "value")
+	}
+
+	if err := c.PutValue(context.Background(), "k", "not a message", "application/x-protobuf"); err == nil {
+		t.Error("PutValue of a non-message = nil, want an error")
+	}
+}
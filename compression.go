@@ -0,0 +1,64 @@
+package raccoon_kv_client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"sync/atomic"
+)
+
+type compression struct {
+	threshold int
+	// unsupported is set once the server rejects a compressed value, after which values are sent as is.
+	unsupported atomic.Bool
+}
+
+// WithCompression gzips values of at least threshold bytes on Put and sends them with a Content-Encoding header.
+// If the server rejects a compressed value with 415 Unsupported Media Type the value is sent again uncompressed and
+// the client stops compressing. Values stored compressed are decompressed on Get unless WithoutDecompression is
+// given. Encrypted values do not compress, so a client with WithEncryption sends them uncompressed.
+func WithCompression(threshold int) Option {
+	return func(c *Client) {
+		c.compression = &compression{threshold: threshold}
+	}
+}
+
+// WithoutDecompression returns values exactly as the server sends them: reads no longer ask for gzip-encoded
+// responses and values stored compressed are returned compressed, e.g. for proxies that pass them through.
+func WithoutDecompression() Option {
+	return func(c *Client) {
+		c.rawValues = true
+	}
+}
+
+// acceptEncoding asks for the value read by request to be gzip-encoded unless the client passes values through.
+func (c *Client) acceptEncoding(request *http.Request) {
+	if c.rawValues {
+		request.Header.Set("accept-encoding", "identity")
+	} else {
+		request.Header.Set("accept-encoding", "gzip")
+	}
+}
+
+// compress gzips data if the client compresses values of its size, returning the body to send and the headers
+// to send it with. Ciphertext is left as it is, since compressing it saves nothing.
+func (c *Client) compress(data []byte, header http.Header) ([]byte, http.Header, bool) {
+	if c.compression == nil || c.keys != nil || len(data) < c.compression.threshold || c.compression.unsupported.Load() {
+		return data, header, false
+	}
+
+	var compressed bytes.Buffer
+
+	writer := gzip.NewWriter(&compressed)
+	_, _ = writer.Write(data)
+	_ = writer.Close()
+
+	header = header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+
+	header.Set("content-encoding", "gzip")
+
+	return compressed.Bytes(), header, true
+}
@@ -0,0 +1,41 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// setDeadline tells the server when the client stops waiting for the answer to request, so it can abandon work
+// nobody will consume.
+func setDeadline(request *http.Request) {
+	if deadline, ok := request.Context().Deadline(); ok {
+		request.Header.Set("x-request-deadline", deadline.UTC().Format(time.RFC3339Nano))
+	}
+}
+
+// pollDuration returns the long-poll url and duration for the next poll of requestUrl, shortening the time the
+// server may hold the poll so that it answers before ctx's deadline.
+func (c *Client) pollDuration(ctx context.Context, requestUrl string) (string, time.Duration) {
+	seconds := c.watchSeconds()
+
+	deadline, ok := ctx.Deadline()
+	if !ok || time.Until(deadline) >= time.Duration(seconds)*time.Second {
+		return requestUrl, time.Duration(seconds) * time.Second
+	}
+
+	seconds = max(int(time.Until(deadline)/time.Second), 1)
+
+	parsed, err := url.Parse(requestUrl)
+	if err != nil {
+		return requestUrl, time.Duration(seconds) * time.Second
+	}
+
+	query := parsed.Query()
+	query.Set("watch", strconv.Itoa(seconds))
+	parsed.RawQuery = query.Encode()
+
+	return parsed.String(), time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,80 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"time"
+)
+
+// debounce wraps emit so that events arriving within quiet of each other are coalesced and only the latest is
+// delivered once no further event has arrived for quiet. The returned stop function waits for the delivery
+// goroutine to exit; an event still pending at that point is only delivered if ctx is still live.
+func debounce(ctx context.Context, quiet time.Duration, emit func(Event)) (debounced func(Event), stop func()) {
+	events := make(chan Event)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		var pending *Event
+
+		var timer *time.Timer
+
+		var fire <-chan time.Time
+
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					if pending != nil && ctx.Err() == nil {
+						emit(*pending)
+					}
+
+					return
+				}
+
+				if pending != nil {
+					event = coalesce(*pending, event)
+				}
+
+				pending = &event
+
+				if timer == nil {
+					timer = time.NewTimer(quiet)
+				} else {
+					timer.Reset(quiet)
+				}
+
+				fire = timer.C
+			case <-fire:
+				emit(*pending)
+
+				pending = nil
+				fire = nil
+			}
+		}
+	}()
+
+	debounced = func(event Event) {
+		events <- event
+	}
+
+	stop = func() {
+		close(events)
+		<-done
+	}
+
+	return debounced, stop
+}
+
+// coalesce merges next into a pending event so that its type still describes the change relative to the state
+// before the pending event.
+func coalesce(pending Event, next Event) Event {
+	switch {
+	case pending.Type == EventCreate && next.Type == EventUpdate:
+		next.Type = EventCreate
+	case pending.Type == EventDelete && next.Type == EventCreate:
+		next.Type = EventUpdate
+	}
+
+	return next
+}
@@ -0,0 +1,80 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDebounce(t *testing.T) {
+	var mu sync.Mutex
+
+	var emitted []Event
+
+	debounced, stop := debounce(context.Background(), 20*time.Millisecond, func(event Event) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		emitted = append(emitted, event)
+	})
+
+	debounced(Event{Type: EventCreate, Version: "1"})
+	debounced(Event{Type: EventUpdate, Version: "2"})
+	debounced(Event{Type: EventUpdate, Version: "3"})
+
+	eventually(t, "the burst to be delivered", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return len(emitted) == 1
+	})
+
+	debounced(Event{Type: EventDelete, Version: "4"})
+	stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	// The burst arrives as a single creation of its latest version, and stop flushes the pending deletion.
+	want := []Event{{Type: EventCreate, Version: "3"}, {Type: EventDelete, Version: "4"}}
+	if !slices.EqualFunc(emitted, want, func(a, b Event) bool { return a.Type == b.Type && a.Version == b.Version }) {
+		t.Errorf("emitted %+v, want %+v", emitted, want)
+	}
+}
+
+func TestDebounceStopAfterCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	emitted := 0
+	debounced, stop := debounce(ctx, time.Hour, func(Event) { emitted++ })
+
+	debounced(Event{Type: EventUpdate})
+	cancel()
+	stop()
+
+	if emitted != 0 {
+		t.Errorf("emitted %d events after the context ended, want 0", emitted)
+	}
+}
+
+func TestCoalesce(t *testing.T) {
+	tests := []struct {
+		pending EventType
+		next    EventType
+		want    EventType
+	}{
+		{EventCreate, EventUpdate, EventCreate},
+		{EventCreate, EventDelete, EventDelete},
+		{EventUpdate, EventUpdate, EventUpdate},
+		{EventUpdate, EventDelete, EventDelete},
+		{EventDelete, EventCreate, EventUpdate},
+	}
+
+	for _, test := range tests {
+		if got := coalesce(Event{Type: test.pending}, Event{Type: test.next}).Type; got != test.want {
+			t.Errorf("coalesce(%v, %v) = %v, want %v", test.pending, test.next, got, test.want)
+		}
+	}
+}
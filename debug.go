@@ -0,0 +1,113 @@
+package raccoon_kv_client
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxDebugBody bounds how much of a request or response body is included in a debug dump.
+const maxDebugBody = 1024
+
+// sensitiveHeaders are replaced in debug dumps so that credentials do not end up in logs.
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// WithDebug writes a dump of every request the client sends and of the response it gets to w: method, URL,
+// headers with credentials redacted, the start of the bodies and how long the server took to answer.
+func WithDebug(w io.Writer) Option {
+	return func(c *Client) {
+		c.debug = &debugWriter{w: w}
+	}
+}
+
+type debugWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// roundTrip sends request with the client's HTTP client, dumping it and its response if debugging is enabled.
+func (c *Client) roundTrip(request *http.Request) (*http.Response, error) {
+	if c.debug == nil {
+		return c.httpClientOrInit().Do(request)
+	}
+
+	var dump bytes.Buffer
+
+	fmt.Fprintf(&dump, "--> %s %s\n", request.Method, request.URL.Redacted())
+	c.dumpHeader(&dump, request.Header)
+
+	if request.GetBody != nil {
+		if body, err := request.GetBody(); err == nil {
+			dumpBody(&dump, body)
+			_ = body.Close()
+		}
+	}
+
+	start := time.Now()
+	response, err := c.httpClientOrInit().Do(request)
+	elapsed := time.Since(start)
+
+	if err != nil {
+		fmt.Fprintf(&dump, "<-- error after %s: %s\n", elapsed, err)
+	} else {
+		fmt.Fprintf(&dump, "<-- %s (%s)\n", response.Status, elapsed)
+		c.dumpHeader(&dump, response.Header)
+
+		mediaType, _, _ := mime.ParseMediaType(response.Header.Get("content-type"))
+		if response.StatusCode != http.StatusSwitchingProtocols && mediaType != "text/event-stream" {
+			snippet, _ := io.ReadAll(io.LimitReader(response.Body, maxDebugBody+1))
+			dumpBody(&dump, bytes.NewReader(snippet))
+
+			response.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(snippet), response.Body), response.Body}
+		}
+	}
+
+	c.debug.mu.Lock()
+	_, _ = c.debug.w.Write(dump.Bytes())
+	c.debug.mu.Unlock()
+
+	return response, err
+}
+
+func (c *Client) dumpHeader(dump *bytes.Buffer, header http.Header) {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := strings.Join(header[name], ", ")
+
+		canonical := http.CanonicalHeaderKey(name)
+		if slices.Contains(sensitiveHeaders, canonical) || (c.apiKey != nil && http.CanonicalHeaderKey(c.apiKey.header) == canonical) {
+			value = "[redacted]"
+		}
+
+		fmt.Fprintf(dump, "%s: %s\n", name, value)
+	}
+}
+
+func dumpBody(dump *bytes.Buffer, body io.Reader) {
+	data, _ := io.ReadAll(io.LimitReader(body, maxDebugBody+1))
+	if len(data) == 0 {
+		return
+	}
+
+	if len(data) > maxDebugBody {
+		fmt.Fprintf(dump, "\n%s... (truncated)\n", data[:maxDebugBody])
+		return
+	}
+
+	fmt.Fprintf(dump, "\n%s\n", data)
+}
@@ -0,0 +1,168 @@
+package raccoon_kv_client
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+)
+
+// encryptionKeyIdHeader records the ID of the key a value was encrypted with as user metadata.
+const encryptionKeyIdHeader = metadataHeaderPrefix + "Encryption-Key-Id"
+
+// encryptionMagic starts every encrypted value, followed by the key ID length, the key ID, the nonce and the
+// sealed value.
+var encryptionMagic = []byte("rkv\x01")
+
+// ErrDecrypt is returned when a value cannot be decrypted, e.g. because it was not encrypted, its key is unknown
+// or it was tampered with.
+var ErrDecrypt = errors.New("cannot decrypt value")
+
+// KeyProvider supplies the AES keys used by WithEncryption. Keys must be 16, 24 or 32 bytes long.
+type KeyProvider interface {
+	// CurrentKey returns the key new values are encrypted with, along with its ID.
+	CurrentKey(ctx context.Context) (id string, key []byte, err error)
+	// Key returns the key with the given ID, for decrypting values written with it.
+	Key(ctx context.Context, id string) ([]byte, error)
+}
+
+// WithEncryption encrypts values with AES-GCM before they are written and decrypts them after they are read, by
+// Get, watches, scans and transactions alike, so the server only ever stores ciphertext. The ID of the key is
+// embedded in each value, and Put also stores it as Encryption-Key-Id metadata, so keys can be rotated: values
+// keep decrypting as long as keys returns their key. Each value is also bound to the key it is stored under, so a
+// value swapped to another key on the server fails to decrypt. Reading a value that was not encrypted fails with
+// ErrDecrypt.
+func WithEncryption(keys KeyProvider) Option {
+	return func(c *Client) {
+		c.keys = keys
+	}
+}
+
+// encrypt seals data, the value of key, with the provider's current key, adding the key ID header to header. It
+// returns data and header unchanged if the client does not encrypt values.
+func (c *Client) encrypt(ctx context.Context, key string, data []byte, header http.Header) ([]byte, http.Header, error) {
+	if c.keys == nil {
+		return data, header, nil
+	}
+
+	id, secret, err := c.keys.CurrentKey(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(id) > 0xffff {
+		return nil, nil, fmt.Errorf("encryption key id is %d bytes long, at most 65535 are allowed", len(id))
+	}
+
+	aead, err := newAEAD(secret)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var sealed bytes.Buffer
+	sealed.Write(encryptionMagic)
+	_ = binary.Write(&sealed, binary.BigEndian, uint16(len(id)))
+	sealed.WriteString(id)
+
+	nonce := make([]byte, aead.NonceSize())
+	_, _ = rand.Read(nonce)
+	sealed.Write(nonce)
+
+	header = header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+
+	header.Set(encryptionKeyIdHeader, id)
+
+	return aead.Seal(sealed.Bytes(), nonce, data, c.additionalData(id, key)), header, nil
+}
+
+// decrypt opens a value sealed by encrypt for key. Missing values are passed through, as is everything if the
+// client does not encrypt values.
+func (c *Client) decrypt(ctx context.Context, key string, data []byte) ([]byte, error) {
+	if c.keys == nil || data == nil {
+		return data, nil
+	}
+
+	rest, ok := bytes.CutPrefix(data, encryptionMagic)
+	if !ok || len(rest) < 2 {
+		return nil, fmt.Errorf("%w: value is not encrypted", ErrDecrypt)
+	}
+
+	idLength := int(binary.BigEndian.Uint16(rest))
+	rest = rest[2:]
+
+	if len(rest) < idLength {
+		return nil, fmt.Errorf("%w: truncated value", ErrDecrypt)
+	}
+
+	id := string(rest[:idLength])
+	rest = rest[idLength:]
+
+	secret, err := c.keys.Key(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: key %q: %w", ErrDecrypt, id, err)
+	}
+
+	aead, err := newAEAD(secret)
+	if err != nil {
+		return nil, fmt.Errorf("%w: key %q: %w", ErrDecrypt, id, err)
+	}
+
+	if len(rest) < aead.NonceSize() {
+		return nil, fmt.Errorf("%w: truncated value", ErrDecrypt)
+	}
+
+	plaintext, err := aead.Open([]byte{}, rest[:aead.NonceSize()], rest[aead.NonceSize():], c.additionalData(id, key))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDecrypt, err)
+	}
+
+	return plaintext, nil
+}
+
+// additionalData authenticates the encryption key ID and the key a value is stored under on the server along with
+// the value.
+func (c *Client) additionalData(id string, key string) []byte {
+	return []byte(id + "\x00" + c.serverKey(key))
+}
+
+// encryptOps returns a copy of ops with the values of puts encrypted.
+func (c *Client) encryptOps(ctx context.Context, ops []TxnOp) ([]TxnOp, error) {
+	if c.keys == nil {
+		return ops, nil
+	}
+
+	encrypted := slices.Clone(ops)
+
+	for i, op := range encrypted {
+		if op.Op != "put" {
+			continue
+		}
+
+		value, _, err := c.encrypt(ctx, op.Key, op.Value, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		encrypted[i].Value = value
+	}
+
+	return encrypted, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
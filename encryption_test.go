@@ -0,0 +1,94 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// kvServer stores values as sent, keyed by their server key.
+type kvServer struct {
+	mu     sync.Mutex
+	values map[string][]byte
+}
+
+func (s *kvServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := strings.TrimPrefix(r.URL.Path, "/kv/")
+
+	switch r.Method {
+	case "PUT":
+		s.values[key], _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	case "GET":
+		value, ok := s.values[key]
+		w.Header().Set("etag", "1")
+
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_, _ = w.Write(value)
+	}
+}
+
+func TestEncryptionBindsKey(t *testing.T) {
+	store := &kvServer{values: map[string][]byte{}}
+
+	server := httptest.NewServer(store)
+	defer server.Close()
+
+	keys := staticKeys{"k1": []byte("0123456789abcdef")}
+
+	c, err := NewClient(server.URL, WithEncryption(keys), WithNamespace("tenant/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+
+	if err := c.Put(ctx, "a", []byte("secret")); err != nil {
+		t.Fatal(err)
+	}
+
+	if data, _, err := c.Get(ctx, "a"); err != nil || string(data) != "secret" {
+		t.Fatalf("Get = %q, %v, want %q", data, err, "secret")
+	}
+
+	store.mu.Lock()
+	store.values["tenant/b"] = store.values["tenant/a"]
+	store.mu.Unlock()
+
+	if _, _, err := c.Get(ctx, "b"); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Get of a value moved to another key = %v, want ErrDecrypt", err)
+	}
+
+	other, err := NewClient(server.URL, WithEncryption(keys), WithNamespace("other/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store.mu.Lock()
+	store.values["other/a"] = store.values["tenant/a"]
+	store.mu.Unlock()
+
+	if _, _, err := other.Get(ctx, "a"); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Get of a value moved to another namespace = %v, want ErrDecrypt", err)
+	}
+
+	if err := c.Copy(ctx, "a", "c"); err != nil {
+		t.Fatal(err)
+	}
+
+	if data, _, err := c.Get(ctx, "c"); err != nil || string(data) != "secret" {
+		t.Errorf("Get of a copy = %q, %v, want %q", data, err, "secret")
+	}
+}
@@ -0,0 +1,194 @@
+package raccoon_kv_client
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// endpointCooldown is how long an endpoint that failed is passed over before requests are sent to it again.
+const endpointCooldown = 30 * time.Second
+
+// endpoint is one server the client can send requests to, together with its health as observed by requests.
+type endpoint struct {
+	rawUrl  string
+	url     *url.URL
+	pending atomic.Int64
+
+	mu       sync.Mutex
+	failedAt time.Time
+}
+
+func (e *endpoint) healthy(now time.Time) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.failedAt.IsZero() || now.Sub(e.failedAt) >= endpointCooldown
+}
+
+func (e *endpoint) status(now time.Time) EndpointStatus {
+	return EndpointStatus{
+		Url:     e.rawUrl,
+		Healthy: e.healthy(now),
+		Pending: int(e.pending.Load()),
+	}
+}
+
+func (e *endpoint) succeeded() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.failedAt = time.Time{}
+}
+
+func (e *endpoint) failed() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.failedAt = time.Now()
+}
+
+// WithEndpoints adds fallback servers that requests fail over to, in the given order, when the server URL is
+// unreachable or answers with a 5xx status. Writes without an idempotency key, such as transactions and lease grants,
+// only fail over when the server could not be reached, so that they are never applied twice. A failed server is
+// skipped for thirty seconds and then tried again, so it is reinstated once it recovers.
+func WithEndpoints(serverUrls ...string) Option {
+	return func(c *Client) {
+		if len(c.endpoints) == 0 {
+			primary, err := url.Parse(c.Url)
+			if err != nil {
+				c.err = fmt.Errorf("invalid server url: %w", err)
+				return
+			}
+
+			c.endpoints = append(c.endpoints, &endpoint{rawUrl: c.Url, url: primary})
+		}
+
+		for _, serverUrl := range serverUrls {
+			serverUrl = strings.TrimSuffix(serverUrl, "/")
+
+			parsed, err := url.Parse(serverUrl)
+			if err != nil {
+				c.err = fmt.Errorf("invalid endpoint url: %w", err)
+				return
+			}
+
+			if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				c.err = fmt.Errorf("invalid endpoint url %q: must be an http or https url with a host", serverUrl)
+				return
+			}
+
+			c.endpoints = append(c.endpoints, &endpoint{rawUrl: serverUrl, url: parsed})
+		}
+	}
+}
+
+// endpointOrder returns the endpoints to try for request: healthy ones first in their configured order, then the
+// ones still cooling down as a last resort. For reads, the endpoint chosen by the balancer goes first.
+func (c *Client) endpointOrder(request *http.Request) []*endpoint {
+	now := time.Now()
+
+	order := make([]*endpoint, 0, len(c.endpoints))
+	var cooling []*endpoint
+
+	for _, e := range c.endpoints {
+		if e.healthy(now) {
+			order = append(order, e)
+		} else {
+			cooling = append(cooling, e)
+		}
+	}
+
+	if c.balancer != nil && len(order) > 1 && (request.Method == "GET" || request.Method == "HEAD") {
+		candidates := make([]EndpointStatus, len(order))
+		for i, e := range order {
+			candidates[i] = e.status(now)
+		}
+
+		if picked := c.balancer.Pick(candidates); picked > 0 && picked < len(order) {
+			reordered := append([]*endpoint{order[picked]}, order[:picked]...)
+			order = append(reordered, order[picked+1:]...)
+		}
+	}
+
+	return append(order, cooling...)
+}
+
+// failover sends request to the configured endpoints, hedging it if it is a Get and hedging is enabled.
+func (c *Client) failover(request *http.Request) (*http.Response, error) {
+	endpoints := c.endpointOrder(request)
+
+	if c.hedgeDelay > 0 && isHedged(request.Context()) {
+		return c.hedge(request, endpoints)
+	}
+
+	return c.tryEndpoints(request, endpoints)
+}
+
+// tryEndpoints sends request to each of endpoints in turn until one answers without a server error. Requests
+// whose body cannot be replayed are only sent to the first endpoint, and writes without an idempotency key only move
+// on to the next endpoint when the connection could not be established, since the server may have applied them.
+func (c *Client) tryEndpoints(request *http.Request, endpoints []*endpoint) (*http.Response, error) {
+	replayable := request.Body == nil || request.GetBody != nil
+
+	for i, e := range endpoints {
+		attempt, err := e.rewrite(request, c.endpoints[0].url)
+		if err != nil {
+			return nil, err
+		}
+
+		e.pending.Add(1)
+		response, err := c.send(attempt)
+		e.pending.Add(-1)
+
+		if err == nil && response.StatusCode < http.StatusInternalServerError {
+			e.succeeded()
+			return response, nil
+		}
+
+		if request.Context().Err() != nil {
+			return response, err
+		}
+
+		e.failed()
+
+		if i == len(endpoints)-1 || !replayable || !(idempotent(request) || dialFailed(err)) {
+			return response, err
+		}
+
+		if err == nil {
+			drainAndClose(response.Body)
+		}
+	}
+
+	return nil, fmt.Errorf("no endpoints configured")
+}
+
+// rewrite returns a copy of request addressed to e instead of the primary server, with a fresh body.
+func (e *endpoint) rewrite(request *http.Request, primary *url.URL) (*http.Request, error) {
+	attempt := request.Clone(request.Context())
+
+	if request.GetBody != nil {
+		body, err := request.GetBody()
+		if err != nil {
+			return nil, err
+		}
+
+		attempt.Body = body
+	}
+
+	attempt.URL.Scheme = e.url.Scheme
+	attempt.URL.Host = e.url.Host
+	attempt.URL.Path = e.url.Path + strings.TrimPrefix(request.URL.Path, primary.Path)
+	if request.URL.RawPath != "" {
+		attempt.URL.RawPath = e.url.EscapedPath() + strings.TrimPrefix(request.URL.RawPath, primary.EscapedPath())
+	}
+
+	attempt.Host = ""
+
+	return attempt, nil
+}
@@ -0,0 +1,72 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestFailover(t *testing.T) {
+	tests := []struct {
+		name        string
+		unreachable bool
+		put         func(c *Client) error
+		failover    bool
+	}{
+		{"read", false, func(c *Client) error { _, _, err := c.Get(context.Background(), "k"); return err }, true},
+		{"write", false, func(c *Client) error { return c.Put(context.Background(), "k", []byte("v")) }, false},
+		{"write with idempotency key", false, func(c *Client) error {
+			return c.PutWithOptions(context.Background(), "k", []byte("v"), IdempotencyKey("put-1"))
+		}, true},
+		{"write to unreachable server", true, func(c *Client) error { return c.Put(context.Background(), "k", []byte("v")) }, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			}))
+			defer primary.Close()
+
+			var fallbackRequests atomic.Int32
+
+			fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fallbackRequests.Add(1)
+
+				if r.Method == "PUT" {
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+
+				w.Header().Set("etag", "1")
+				_, _ = w.Write([]byte("v"))
+			}))
+			defer fallback.Close()
+
+			if test.unreachable {
+				primary.Close()
+			}
+
+			c, err := NewClient(primary.URL, WithEndpoints(fallback.URL))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = test.put(c)
+
+			if failedOver := fallbackRequests.Load() > 0; failedOver != test.failover {
+				t.Errorf("failed over = %t, want %t", failedOver, test.failover)
+			}
+
+			if test.failover && err != nil {
+				t.Errorf("err = %v, want nil", err)
+			}
+
+			if !test.failover && err == nil {
+				t.Errorf("err = nil, want the primary's server error")
+			}
+		})
+	}
+}
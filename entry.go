@@ -0,0 +1,93 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const metadataHeaderPrefix = "X-Raccoon-Meta-"
+
+// Entry is a value together with the metadata the server stores alongside it.
+type Entry struct {
+	Key         string
+	Value       []byte
+	Version     string
+	ContentType string
+	Size        int64
+	CreatedAt   time.Time
+	ModifiedAt  time.Time
+	// Metadata holds user metadata headers with their X-Raccoon-Meta- prefix removed.
+	Metadata map[string]string
+}
+
+// GetEntry returns the value of key along with its metadata. It returns ErrNotFound if the key does not exist.
+func (c *Client) GetEntry(ctx context.Context, key string) (*Entry, error) {
+	keyUrl, err := c.keyUrl(key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.getEntry(ctx, key, keyUrl)
+}
+
+func (c *Client) getEntry(ctx context.Context, key string, url string) (*Entry, error) {
+	ctx, cancel := withTimeout(ctx, c.readTimeoutFor(ctx))
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c.acceptEncoding(request)
+
+	response, err := c.doRead(request)
+	if err != nil {
+		return nil, err
+	}
+	defer drainAndClose(response.Body)
+
+	switch response.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, newResponseError(response)
+	}
+
+	data, err := c.readValue(ctx, key, response)
+	if err != nil {
+		return nil, err
+	}
+
+	return entryFromHeader(key, data, response.Header), nil
+}
+
+func entryFromHeader(key string, data []byte, header http.Header) *Entry {
+	entry := &Entry{
+		Key:         key,
+		Value:       data,
+		Version:     header.Get("etag"),
+		ContentType: header.Get("content-type"),
+		Size:        int64(len(data)),
+		Metadata:    map[string]string{},
+	}
+
+	if createdAt, err := http.ParseTime(header.Get("x-raccoon-created")); err == nil {
+		entry.CreatedAt = createdAt
+	}
+
+	if modifiedAt, err := http.ParseTime(header.Get("last-modified")); err == nil {
+		entry.ModifiedAt = modifiedAt
+	}
+
+	for name, values := range header {
+		if strings.HasPrefix(name, metadataHeaderPrefix) && len(values) > 0 {
+			entry.Metadata[strings.TrimPrefix(name, metadataHeaderPrefix)] = values[0]
+		}
+	}
+
+	return entry
+}
@@ -0,0 +1,119 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"sync"
+	"time"
+)
+
+// maxCachedDataKeys bounds how many unwrapped data keys EnvelopeKeys keeps in memory.
+const maxCachedDataKeys = 1024
+
+// KEK is a key encryption key held by an external key management service, which wraps the data keys that
+// values are encrypted with so that they can be stored alongside the values.
+type KEK interface {
+	// Wrap encrypts dataKey with the key encryption key.
+	Wrap(ctx context.Context, dataKey []byte) ([]byte, error)
+	// Unwrap decrypts a data key returned by Wrap.
+	Unwrap(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+// EnvelopeKeys is a KeyProvider for envelope encryption: values are encrypted with random 256-bit data keys, and
+// each data key travels with its values wrapped by a KEK, so only the KEK has to be managed. Data keys are
+// cached, so the key management service is only called when a key is rotated or first seen.
+type EnvelopeKeys struct {
+	kek         KEK
+	rotateEvery time.Duration
+
+	mu        sync.Mutex
+	currentId string
+	createdAt time.Time
+	cache     map[string][]byte
+}
+
+// NewEnvelopeKeys returns envelope keys wrapped by kek, for use with WithEncryption. A new data key is generated
+// every rotateEvery; zero keeps the first data key until Rotate is called.
+func NewEnvelopeKeys(kek KEK, rotateEvery time.Duration) *EnvelopeKeys {
+	return &EnvelopeKeys{
+		kek:         kek,
+		rotateEvery: rotateEvery,
+		cache:       map[string][]byte{},
+	}
+}
+
+// Rotate makes the next write generate a new data key. Values written with earlier data keys remain readable.
+func (k *EnvelopeKeys) Rotate() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.currentId = ""
+}
+
+// CurrentKey returns the current data key, identified by its wrapped form, generating a new one if it is due
+// for rotation.
+func (k *EnvelopeKeys) CurrentKey(ctx context.Context) (id string, key []byte, err error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.currentId != "" && (k.rotateEvery <= 0 || time.Since(k.createdAt) < k.rotateEvery) {
+		return k.currentId, k.cache[k.currentId], nil
+	}
+
+	key = make([]byte, 32)
+	_, _ = rand.Read(key)
+
+	wrapped, err := k.kek.Wrap(ctx, key)
+	if err != nil {
+		return "", nil, err
+	}
+
+	k.currentId = base64.RawURLEncoding.EncodeToString(wrapped)
+	k.createdAt = time.Now()
+	k.store(k.currentId, key)
+
+	return k.currentId, key, nil
+}
+
+// Key unwraps the data key identified by id, or returns it from the cache.
+func (k *EnvelopeKeys) Key(ctx context.Context, id string) ([]byte, error) {
+	k.mu.Lock()
+	key, ok := k.cache[id]
+	k.mu.Unlock()
+
+	if ok {
+		return key, nil
+	}
+
+	wrapped, err := base64.RawURLEncoding.DecodeString(id)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err = k.kek.Unwrap(ctx, wrapped)
+	if err != nil {
+		return nil, err
+	}
+
+	k.mu.Lock()
+	k.store(id, key)
+	k.mu.Unlock()
+
+	return key, nil
+}
+
+// store caches key, evicting another key other than the current one if the cache is full. It must be called
+// with k.mu held.
+func (k *EnvelopeKeys) store(id string, key []byte) {
+	if len(k.cache) >= maxCachedDataKeys {
+		for cached := range k.cache {
+			if cached != k.currentId && cached != id {
+				delete(k.cache, cached)
+				break
+			}
+		}
+	}
+
+	k.cache[id] = key
+}
@@ -0,0 +1,83 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Endpoints returns the status of the endpoints configured with WithEndpoints, the server URL first. It returns
+// nil for a client with a single server.
+func (c *Client) Endpoints() []EndpointStatus {
+	now := time.Now()
+
+	var statuses []EndpointStatus
+	for _, e := range c.endpoints {
+		statuses = append(statuses, e.status(now))
+	}
+
+	return statuses
+}
+
+// StartHealthChecks probes every endpoint configured with WithEndpoints at /healthz once per interval until ctx
+// ends, so endpoints are taken out of rotation before requests fail on them and reinstated as soon as they
+// recover.
+func (c *Client) StartHealthChecks(ctx context.Context, interval time.Duration) {
+	if len(c.endpoints) == 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			c.checkHealth(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (c *Client) checkHealth(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	for _, e := range c.endpoints {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if c.probe(ctx, e) {
+				e.succeeded()
+			} else if ctx.Err() == nil {
+				e.failed()
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+func (c *Client) probe(ctx context.Context, e *endpoint) bool {
+	ctx, cancel := withTimeout(ctx, c.readTimeoutFor(ctx))
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, "GET", e.url.JoinPath("healthz").String(), nil)
+	if err != nil {
+		return false
+	}
+
+	response, err := c.send(request)
+	if err != nil {
+		return false
+	}
+	defer drainAndClose(response.Body)
+
+	return response.StatusCode >= 200 && response.StatusCode < 300
+}
@@ -0,0 +1,127 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+type hedgeKey struct{}
+
+// WithHedging makes Get send a second request to the next endpoint if the first has not been answered within
+// delay, using whichever response arrives first. It only has an effect together with WithEndpoints.
+func WithHedging(delay time.Duration) Option {
+	return func(c *Client) {
+		c.hedgeDelay = delay
+	}
+}
+
+func withHedging(ctx context.Context) context.Context {
+	return context.WithValue(ctx, hedgeKey{}, true)
+}
+
+func isHedged(ctx context.Context) bool {
+	hedged, _ := ctx.Value(hedgeKey{}).(bool)
+	return hedged
+}
+
+type hedgeResult struct {
+	attempt  int
+	response *http.Response
+	err      error
+}
+
+// hedge sends request along endpoints and, if no answer arrives within the hedging delay, a second copy starting
+// at the next endpoint. The first successful response wins and the other request is cancelled.
+func (c *Client) hedge(request *http.Request, endpoints []*endpoint) (*http.Response, error) {
+	results := make(chan hedgeResult, 2)
+	var cancels []context.CancelFunc
+
+	launch := func(endpoints []*endpoint) {
+		ctx, cancel := context.WithCancel(request.Context())
+		attempt := len(cancels)
+		cancels = append(cancels, cancel)
+
+		go func() {
+			response, err := c.tryEndpoints(request.WithContext(ctx), endpoints)
+			results <- hedgeResult{attempt: attempt, response: response, err: err}
+		}()
+	}
+
+	launch(endpoints)
+
+	timer := time.NewTimer(c.hedgeDelay)
+	defer timer.Stop()
+
+	var last hedgeResult
+
+	for received := 0; received < len(cancels); {
+		select {
+		case <-timer.C:
+			if len(endpoints) > 1 && len(cancels) == 1 {
+				launch(append(endpoints[1:len(endpoints):len(endpoints)], endpoints[0]))
+			}
+		case result := <-results:
+			received++
+
+			if result.err == nil && result.response.StatusCode < http.StatusInternalServerError {
+				for i, cancel := range cancels {
+					if i != result.attempt {
+						cancel()
+					}
+				}
+
+				if last.response != nil {
+					_ = last.response.Body.Close()
+				}
+
+				go discardHedged(results, len(cancels)-received)
+
+				result.response.Body = &cancelOnClose{ReadCloser: result.response.Body, cancel: cancels[result.attempt]}
+				return result.response, nil
+			}
+
+			if last.response != nil {
+				_ = last.response.Body.Close()
+			}
+
+			last = result
+		}
+	}
+
+	for _, cancel := range cancels[:len(cancels)-1] {
+		cancel()
+	}
+
+	if last.err != nil {
+		cancels[len(cancels)-1]()
+		return nil, last.err
+	}
+
+	last.response.Body = &cancelOnClose{ReadCloser: last.response.Body, cancel: cancels[len(cancels)-1]}
+	return last.response, nil
+}
+
+// discardHedged closes the responses of n hedged requests that lost the race.
+func discardHedged(results <-chan hedgeResult, n int) {
+	for range n {
+		result := <-results
+		if result.err == nil {
+			drainAndClose(result.response.Body)
+		}
+	}
+}
+
+// cancelOnClose releases the context of a hedged request once its response body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+
+	return err
+}
@@ -0,0 +1,98 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// delayedServer answers after delay with value, counting its requests and the ones abandoned by the client.
+type delayedServer struct {
+	delay     time.Duration
+	value     string
+	requests  atomic.Int32
+	cancelled atomic.Int32
+}
+
+func (s *delayedServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.requests.Add(1)
+
+	select {
+	case <-time.After(s.delay):
+	case <-r.Context().Done():
+		s.cancelled.Add(1)
+		return
+	}
+
+	if r.Method == "PUT" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	w.Header().Set("etag", "1")
+	_, _ = w.Write([]byte(s.value))
+}
+
+func TestHedging(t *testing.T) {
+	tests := []struct {
+		name          string
+		primaryDelay  time.Duration
+		put           bool
+		want          string
+		wantFallbacks int32
+	}{
+		{"fast primary", 0, false, "primary", 0},
+		{"slow primary", 500 * time.Millisecond, false, "fallback", 1},
+		{"writes are not hedged", 100 * time.Millisecond, true, "", 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			primary := &delayedServer{delay: test.primaryDelay, value: "primary"}
+			fallback := &delayedServer{value: "fallback"}
+
+			primaryServer := httptest.NewServer(primary)
+			defer primaryServer.Close()
+
+			fallbackServer := httptest.NewServer(fallback)
+			defer fallbackServer.Close()
+
+			c, err := NewClient(primaryServer.URL, WithEndpoints(fallbackServer.URL), WithHedging(20*time.Millisecond))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			start := time.Now()
+
+			if test.put {
+				if err := c.Put(context.Background(), "k", []byte("v")); err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				data, _, err := c.Get(context.Background(), "k")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if string(data) != test.want {
+					t.Errorf("Get = %q, want %q", data, test.want)
+				}
+
+				if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+					t.Errorf("Get took %s, want the faster response", elapsed)
+				}
+			}
+
+			if got := fallback.requests.Load(); got != test.wantFallbacks {
+				t.Errorf("fallback requests = %d, want %d", got, test.wantFallbacks)
+			}
+
+			if test.wantFallbacks > 0 {
+				eventually(t, "the losing request to be cancelled", func() bool { return primary.cancelled.Load() == 1 })
+			}
+		})
+	}
+}
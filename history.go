@@ -0,0 +1,61 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Revision describes one past version of a key.
+type Revision struct {
+	Version    string    `json:"version"`
+	ModifiedAt time.Time `json:"modified_at"`
+	Size       int64     `json:"size"`
+	Deleted    bool      `json:"deleted"`
+}
+
+type historyPage struct {
+	Revisions []Revision `json:"revisions"`
+}
+
+// History returns up to limit revisions of key, newest first. A limit of zero lets the server pick its default.
+func (c *Client) History(ctx context.Context, key string, limit int) ([]Revision, error) {
+	query := url.Values{}
+	query.Set("history", "")
+
+	if limit > 0 {
+		query.Set("limit", fmt.Sprintf("%d", limit))
+	}
+
+	keyUrl, err := c.keyUrl(key, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var page historyPage
+	if err := c.getJSON(ctx, keyUrl, &page); err != nil {
+		return nil, err
+	}
+
+	return page.Revisions, nil
+}
+
+// GetAtVersion returns the value key held at version. It returns ErrNotFound if the server does not retain
+// that revision.
+func (c *Client) GetAtVersion(ctx context.Context, key string, version string) ([]byte, error) {
+	query := url.Values{}
+	query.Set("version", version)
+
+	keyUrl, err := c.keyUrl(key, query)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := c.getEntry(ctx, key, keyUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	return entry.Value, nil
+}
@@ -0,0 +1,46 @@
+package raccoon_kv_client
+
+import (
+	"net/http"
+	"time"
+)
+
+// Hooks are callbacks invoked at points of the client's lifecycle, e.g. for custom metrics, logging or auditing.
+// Any of them may be nil. They are called synchronously and concurrently, so they should return quickly.
+type Hooks struct {
+	// OnRequestStart is called before a request is sent, including its retries.
+	OnRequestStart func(request *http.Request)
+	// OnRequestEnd is called once a request has completed, with the final response or error.
+	OnRequestEnd func(request *http.Request, response *http.Response, err error, duration time.Duration)
+	// OnRetry is called before request is sent again as attempt number attempt, with the failed response or
+	// error of the previous attempt. The response body must not be read.
+	OnRetry func(request *http.Request, attempt int, response *http.Response, err error)
+	// OnWatchEvent is called for every event a watch delivers.
+	OnWatchEvent func(event Event)
+}
+
+// WithHooks installs hooks on the client, replacing hooks given earlier.
+func WithHooks(hooks Hooks) Option {
+	return func(c *Client) {
+		c.hooks = hooks
+	}
+}
+
+// hook wraps send so that the request hooks are called around every request.
+func (c *Client) hook(send RoundTripFunc) RoundTripFunc {
+	return func(request *http.Request) (*http.Response, error) {
+		if c.hooks.OnRequestStart != nil {
+			c.hooks.OnRequestStart(request)
+		}
+
+		start := time.Now()
+
+		response, err := send(request)
+
+		if c.hooks.OnRequestEnd != nil {
+			c.hooks.OnRequestEnd(request, response, err, time.Since(start))
+		}
+
+		return response, err
+	}
+}
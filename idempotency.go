@@ -0,0 +1,40 @@
+package raccoon_kv_client
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// WithIdempotencyKeys sends a generated Idempotency-Key header with every write so the server can deduplicate a
+// write that is sent again. Writes carrying a key are retried by the retry policy like reads, even after errors
+// that leave it unclear whether the server applied them. Only enable it if the server deduplicates writes.
+func WithIdempotencyKeys() Option {
+	return func(c *Client) {
+		c.idempotencyKeys = true
+	}
+}
+
+// IdempotencyKey sends key as the Idempotency-Key of one write, e.g. to deduplicate a write the application
+// itself retries.
+func IdempotencyKey(key string) CallOption {
+	return func(config *callConfig) {
+		config.idempotencyKey = key
+	}
+}
+
+// setIdempotencyKey gives the write request a key that stays the same across all attempts to send it.
+func (c *Client) setIdempotencyKey(request *http.Request) {
+	key := callOptions(request.Context()).idempotencyKey
+
+	if key == "" && c.idempotencyKeys {
+		var nonce [16]byte
+		_, _ = rand.Read(nonce[:])
+
+		key = hex.EncodeToString(nonce[:])
+	}
+
+	if key != "" {
+		request.Header.Set("idempotency-key", key)
+	}
+}
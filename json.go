@@ -0,0 +1,41 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrDecode is returned by GetJSON when the stored value cannot be decoded, as opposed to failing to fetch it.
+var ErrDecode = errors.New("cannot decode value")
+
+// GetJSON fetches key and decodes its JSON value into v. It returns ErrNotFound if the key does not exist and an
+// error wrapping ErrDecode if the value is not valid JSON for v.
+func (c *Client) GetJSON(ctx context.Context, key string, v any) error {
+	data, _, err := c.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrDecode, key, err)
+	}
+
+	return nil
+}
+
+// PutJSON encodes v as JSON and writes it to key with an application/json content type.
+func (c *Client) PutJSON(ctx context.Context, key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	header := http.Header{}
+	header.Set("content-type", "application/json")
+
+	_, err = c.put(ctx, key, nil, data, header)
+	return err
+}
@@ -0,0 +1,77 @@
+package raccoon_kv_client
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxKeyLength is the longest key in bytes the server accepts.
+const maxKeyLength = 1024
+
+// ErrInvalidKey is returned, wrapped with the reason, for keys the server cannot store.
+var ErrInvalidKey = errors.New("invalid key")
+
+// ValidateKey checks that key can be stored. Keys are UTF-8 strings of up to 1024 bytes without control
+// characters. A key may be hierarchical, with segments separated by "/", but must not start or end with "/",
+// contain empty segments, or contain "." or ".." segments.
+func ValidateKey(key string) error {
+	switch {
+	case key == "":
+		return fmt.Errorf("%w: empty", ErrInvalidKey)
+	case len(key) > maxKeyLength:
+		return fmt.Errorf("%w: longer than %d bytes", ErrInvalidKey, maxKeyLength)
+	case !utf8.ValidString(key):
+		return fmt.Errorf("%w: not valid UTF-8", ErrInvalidKey)
+	case strings.ContainsFunc(key, unicode.IsControl):
+		return fmt.Errorf("%w: contains control characters", ErrInvalidKey)
+	}
+
+	for _, segment := range strings.Split(key, "/") {
+		switch segment {
+		case "":
+			return fmt.Errorf("%w: %q has an empty path segment", ErrInvalidKey, key)
+		case ".", "..":
+			return fmt.Errorf("%w: %q has a %q path segment", ErrInvalidKey, key, segment)
+		}
+	}
+
+	return nil
+}
+
+// validatePrefix checks that prefix can start a key: it is empty, or a valid key that may end with "/".
+func validatePrefix(prefix string) error {
+	if prefix == "" {
+		return nil
+	}
+
+	return ValidateKey(strings.TrimSuffix(prefix, "/"))
+}
+
+// escapeKey escapes each segment of key for use in a URL path, keeping the separating slashes.
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// keyUrl returns the URL of key on the server, within the client's namespace, with query appended, or an error if
+// key is invalid.
+func (c *Client) keyUrl(key string, query url.Values) (string, error) {
+	if err := ValidateKey(c.serverKey(key)); err != nil {
+		return "", err
+	}
+
+	keyUrl := fmt.Sprintf("%s/kv/%s", c.Url, escapeKey(c.serverKey(key)))
+	if len(query) > 0 {
+		keyUrl += "?" + query.Encode()
+	}
+
+	return keyUrl, nil
+}
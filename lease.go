@@ -0,0 +1,158 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ErrLeaseExpired is reported by Lease.Err when the server no longer knows the lease, e.g. because keepalives
+// could not be delivered within its TTL.
+var ErrLeaseExpired = errors.New("lease expired")
+
+// Lease is a server-side lease kept alive by the client. Keys written through the lease are removed by the
+// server once the lease is revoked or expires.
+type Lease struct {
+	ID  string
+	TTL time.Duration
+
+	client *Client
+	done   chan struct{}
+
+	mu  sync.Mutex
+	err error
+}
+
+type leaseGrant struct {
+	ID  string `json:"id"`
+	TTL int64  `json:"ttl"`
+}
+
+// GrantLease creates a lease with the given ttl and keeps it alive in the background until ctx ends, at which
+// point the lease is revoked and all keys attached to it expire.
+func (c *Client) GrantLease(ctx context.Context, ttl time.Duration) (*Lease, error) {
+	if ttl < time.Second {
+		return nil, fmt.Errorf("ttl must be at least one second, got %s", ttl)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/lease?ttl=%d", c.Url, int64(ttl/time.Second)), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer drainAndClose(response.Body)
+
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusCreated {
+		return nil, newResponseError(response)
+	}
+
+	var grant leaseGrant
+	if err := json.NewDecoder(response.Body).Decode(&grant); err != nil {
+		return nil, err
+	}
+
+	lease := &Lease{
+		ID:     grant.ID,
+		TTL:    time.Duration(grant.TTL) * time.Second,
+		client: c,
+		done:   make(chan struct{}),
+	}
+
+	if lease.TTL <= 0 {
+		lease.TTL = ttl
+	}
+
+	go lease.keepAlive(ctx)
+
+	return lease, nil
+}
+
+// Put writes data to key and attaches it to the lease.
+func (l *Lease) Put(ctx context.Context, key string, data []byte) error {
+	query := url.Values{}
+	query.Set("lease", l.ID)
+
+	_, err := l.client.put(ctx, key, query, data, nil)
+	return err
+}
+
+// Done is closed once the lease has been revoked or has expired.
+func (l *Lease) Done() <-chan struct{} {
+	return l.done
+}
+
+// Err returns why the lease ended: the context error after revocation, ErrLeaseExpired if the server dropped it,
+// or nil while it is still alive.
+func (l *Lease) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.err
+}
+
+func (l *Lease) keepAlive(ctx context.Context) {
+	defer close(l.done)
+
+	ticker := time.NewTicker(l.TTL / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			l.revoke(ctx)
+			l.setErr(ctx.Err())
+			return
+		case <-ticker.C:
+		}
+
+		response, err := l.client.doWrite(ctx, "PUT", fmt.Sprintf("%s/lease/%s", l.client.Url, l.ID), nil, nil)
+		if err != nil {
+			if ctx.Err() == nil {
+				l.client.log().Warn("failed to refresh lease", slog.String("lease", l.ID), slog.String("err", err.Error()), requestIDAttr(err))
+			}
+			continue
+		}
+
+		switch response.StatusCode {
+		case http.StatusNoContent, http.StatusOK:
+		case http.StatusNotFound:
+			l.client.log().Error("lease expired on server", slog.String("lease", l.ID), responseRequestIDAttr(response))
+			l.setErr(ErrLeaseExpired)
+			return
+		default:
+			l.client.log().Warn("failed to refresh lease", slog.String("lease", l.ID), slog.Int("status", response.StatusCode), responseRequestIDAttr(response))
+		}
+	}
+}
+
+func (l *Lease) revoke(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Second*10)
+	defer cancel()
+
+	response, err := l.client.doWrite(ctx, "DELETE", fmt.Sprintf("%s/lease/%s", l.client.Url, l.ID), nil, nil)
+	if err != nil {
+		l.client.log().Error("failed to revoke lease", slog.String("lease", l.ID), slog.String("err", err.Error()), requestIDAttr(err))
+		return
+	}
+
+	if response.StatusCode != http.StatusNoContent && response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNotFound {
+		l.client.log().Error("failed to revoke lease", slog.String("lease", l.ID), slog.Int("status", response.StatusCode), responseRequestIDAttr(response))
+	}
+}
+
+func (l *Lease) setErr(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.err = err
+}
@@ -0,0 +1,60 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"log/slog"
+)
+
+// WithLogger makes the client log through logger instead of slog's default logger. Watches add the watched key
+// to every message.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// WithLogLevel drops the client's log messages below level, independently of the logger's own configuration.
+// Routine messages, such as a long-poll that ended without changes, are logged at debug level and transient
+// failures that are retried at warn level.
+func WithLogLevel(level slog.Level) Option {
+	return func(c *Client) {
+		c.logLevel = &level
+	}
+}
+
+// WithQuietLogging only logs genuine errors, such as a watch giving up or a lease expiring, and suppresses
+// routine reconnect messages. It is shorthand for WithLogLevel(slog.LevelError).
+func WithQuietLogging() Option {
+	return WithLogLevel(slog.LevelError)
+}
+
+func (c *Client) log() *slog.Logger {
+	logger := c.logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	if c.logLevel != nil {
+		logger = slog.New(&levelHandler{level: *c.logLevel, Handler: logger.Handler()})
+	}
+
+	return logger
+}
+
+// levelHandler drops records below level before they reach Handler.
+type levelHandler struct {
+	level slog.Level
+	slog.Handler
+}
+
+func (h *levelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level && h.Handler.Enabled(ctx, level)
+}
+
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &levelHandler{level: h.level, Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return &levelHandler{level: h.level, Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,108 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+// RequestMetric describes one request the client sent, including any retries.
+type RequestMetric struct {
+	// Operation is what the request did: "get", "head", "put", "delete", "post", "txn" or "watch".
+	Operation string
+	// StatusCode is the status the server answered with, or zero if no response was received.
+	StatusCode int
+	// ErrorType classifies a failed request: "timeout", "canceled", "network", "circuit_open", "rate_limited",
+	// "server_error", "client_error" or "other". It is empty for requests that succeeded, which includes a key
+	// that was not found or a condition that did not hold.
+	ErrorType string
+	Duration  time.Duration
+}
+
+// MetricsCollector receives measurements from the client, e.g. to export them to Prometheus as request counters,
+// error counters by type, latency histograms and an active-watch gauge. Its methods are called concurrently.
+type MetricsCollector interface {
+	ObserveRequest(metric RequestMetric)
+	// WatchesChanged adds delta, which may be negative, to the number of active watches.
+	WatchesChanged(delta int)
+}
+
+// WithMetrics reports request and watch measurements to collector.
+func WithMetrics(collector MetricsCollector) Option {
+	return func(c *Client) {
+		c.metrics = collector
+	}
+}
+
+// measure wraps send so that the outcome of every request is reported to the client's collector.
+func (c *Client) measure(send RoundTripFunc) RoundTripFunc {
+	return func(request *http.Request) (*http.Response, error) {
+		start := time.Now()
+
+		response, err := send(request)
+
+		metric := RequestMetric{
+			Operation: operationName(request),
+			ErrorType: errorType(response, err),
+			Duration:  time.Since(start),
+		}
+
+		if response != nil {
+			metric.StatusCode = response.StatusCode
+		}
+
+		c.metrics.ObserveRequest(metric)
+
+		return response, err
+	}
+}
+
+// watchStarted counts n new watches as active and returns a function that counts them as stopped.
+func (c *Client) watchStarted(n int) func() {
+	if c.metrics == nil {
+		return func() {}
+	}
+
+	c.metrics.WatchesChanged(n)
+
+	return func() {
+		c.metrics.WatchesChanged(-n)
+	}
+}
+
+func errorType(response *http.Response, err error) string {
+	var netErr net.Error
+
+	switch {
+	case err == nil:
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, ErrCircuitOpen):
+		return "circuit_open"
+	case errors.Is(err, ErrRateLimited):
+		return "rate_limited"
+	case errors.As(err, &netErr):
+		if netErr.Timeout() {
+			return "timeout"
+		}
+
+		return "network"
+	default:
+		return "other"
+	}
+
+	switch {
+	case response.StatusCode >= http.StatusInternalServerError:
+		return "server_error"
+	case response.StatusCode == http.StatusNotFound, response.StatusCode == http.StatusPreconditionFailed:
+		return ""
+	case response.StatusCode >= http.StatusBadRequest:
+		return "client_error"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,19 @@
+package raccoon_kv_client
+
+import "net/http"
+
+// RoundTripFunc sends a request and returns the server's response.
+type RoundTripFunc func(request *http.Request) (*http.Response, error)
+
+// Middleware wraps the function that sends requests, so cross-cutting concerns such as caching, metrics or fault
+// injection can be layered around every request. A middleware may modify the request, answer it itself without
+// calling next, or inspect and replace the response.
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// WithMiddleware adds middleware around every attempt to send a request, including retries and watch polls. The
+// first middleware given is the outermost. Credentials and signatures are added after all middleware has run.
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(c *Client) {
+		c.middleware = append(c.middleware, middleware...)
+	}
+}
@@ -0,0 +1,253 @@
+package raccoon_kv_client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ErrEncryptedMultipart is returned by PutLarge on a client that encrypts values, since values are encrypted as a
+// whole and cannot be uploaded in parts.
+var ErrEncryptedMultipart = errors.New("encrypted values cannot be uploaded in parts")
+
+// PutLargeOptions configures a multipart upload made with PutLarge. Zero fields take their defaults.
+type PutLargeOptions struct {
+	// PartSize is the size of every part but the last. It defaults to 64MiB.
+	PartSize int64
+	// Concurrency is the number of parts uploaded at once, each held in memory. It defaults to four.
+	Concurrency int
+	// PartAttempts is the number of times a part is sent before the upload fails. It defaults to three.
+	PartAttempts int
+}
+
+type uploadStart struct {
+	UploadId string `json:"upload_id"`
+}
+
+type uploadPart struct {
+	Part    int    `json:"part"`
+	Version string `json:"etag"`
+}
+
+type uploadCompletion struct {
+	Parts []uploadPart `json:"parts"`
+}
+
+type uploadResult struct {
+	Version string `json:"version"`
+}
+
+// PutLarge writes the value read from r to key as a multipart upload: the server hands out an upload ID, the value
+// is uploaded in parts, several at a time, each retried on its own if it fails, and the server then assembles the
+// parts. This keeps every request below the body limits of proxies on the way. An upload that fails is aborted so
+// the server can discard its parts.
+func (c *Client) PutLarge(ctx context.Context, key string, r io.Reader, opts PutLargeOptions) (err error) {
+	if c.keys != nil {
+		return ErrEncryptedMultipart
+	}
+
+	opts = opts.withDefaults()
+	r = limitReader(r, c.maxPutSize)
+
+	query := url.Values{}
+	query.Set("uploads", "")
+
+	uploadsUrl, err := c.keyUrl(key, query)
+	if err != nil {
+		return err
+	}
+
+	defer c.invalidate(key)
+
+	var size int64
+	var result uploadResult
+
+	if c.audit != nil {
+		record := c.auditRecord(ctx, "put", key, nil)
+
+		defer func() {
+			record.Size = int(size)
+
+			header := http.Header{}
+			header.Set("etag", result.Version)
+
+			c.finishAudit(record, header, err)
+		}()
+	}
+
+	var start uploadStart
+	if err := c.postJSON(ctx, uploadsUrl, struct{}{}, &start); err != nil {
+		return err
+	}
+
+	parts, size, err := c.uploadParts(ctx, key, start.UploadId, r, opts)
+	if err != nil {
+		c.abortUpload(context.WithoutCancel(ctx), key, start.UploadId)
+		return err
+	}
+
+	query = url.Values{}
+	query.Set("upload_id", start.UploadId)
+	query.Set("complete", "")
+
+	completeUrl, err := c.keyUrl(key, query)
+	if err != nil {
+		return err
+	}
+
+	if err := c.postJSON(ctx, completeUrl, uploadCompletion{Parts: parts}, &result); err != nil {
+		c.abortUpload(context.WithoutCancel(ctx), key, start.UploadId)
+		return err
+	}
+
+	return nil
+}
+
+func (opts PutLargeOptions) withDefaults() PutLargeOptions {
+	if opts.PartSize <= 0 {
+		opts.PartSize = 64 << 20
+	}
+
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+
+	if opts.PartAttempts <= 0 {
+		opts.PartAttempts = 3
+	}
+
+	return opts
+}
+
+// uploadParts reads r part by part and uploads up to opts.Concurrency parts at once, returning the parts in order
+// and the total size of the value.
+func (c *Client) uploadParts(ctx context.Context, key string, uploadId string, r io.Reader, opts PutLargeOptions) ([]uploadPart, int64, error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		parts []uploadPart
+		size  int64
+	)
+
+	slots := make(chan struct{}, opts.Concurrency)
+
+	for number := 1; ; number++ {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+
+		data := make([]byte, opts.PartSize)
+
+		n, err := io.ReadFull(r, data)
+		if err == io.EOF && number > 1 {
+			<-slots
+			break
+		}
+
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			<-slots
+			cancel(err)
+			break
+		}
+
+		size += int64(n)
+
+		mu.Lock()
+		parts = append(parts, uploadPart{Part: number})
+		mu.Unlock()
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			version, err := c.uploadPart(ctx, key, uploadId, number, data[:n], opts.PartAttempts)
+			if err != nil {
+				cancel(fmt.Errorf("part %d: %w", number, err))
+				return
+			}
+
+			mu.Lock()
+			parts[number-1].Version = version
+			mu.Unlock()
+		}()
+
+		if n < len(data) {
+			break
+		}
+	}
+
+	wg.Wait()
+
+	if err := context.Cause(ctx); err != nil {
+		return nil, 0, err
+	}
+
+	return parts, size, nil
+}
+
+// uploadPart sends one part of an upload, sending it again up to attempts times in all if it fails.
+func (c *Client) uploadPart(ctx context.Context, key string, uploadId string, number int, data []byte, attempts int) (string, error) {
+	query := url.Values{}
+	query.Set("upload_id", uploadId)
+	query.Set("part", fmt.Sprintf("%d", number))
+
+	partUrl, err := c.keyUrl(key, query)
+	if err != nil {
+		return "", err
+	}
+
+	backoff := c.Backoff
+	if backoff == nil {
+		backoff = DefaultBackoff
+	}
+
+	for failures := 1; ; failures++ {
+		response, err := c.doWrite(ctx, "PUT", partUrl, bytes.NewReader(data), c.addChecksum(data, nil))
+		if err == nil && response.StatusCode == http.StatusNoContent {
+			return response.Header.Get("etag"), nil
+		}
+
+		if err == nil {
+			err = newResponseError(response)
+		}
+
+		if failures >= attempts || ctx.Err() != nil {
+			return "", err
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(backoff.Delay(failures)):
+		}
+	}
+}
+
+// abortUpload asks the server to discard the parts of a failed upload. Failing to do so is not reported.
+func (c *Client) abortUpload(ctx context.Context, key string, uploadId string) {
+	query := url.Values{}
+	query.Set("upload_id", uploadId)
+
+	uploadUrl, err := c.keyUrl(key, query)
+	if err != nil {
+		return
+	}
+
+	_, _ = c.doWrite(ctx, "DELETE", uploadUrl, nil, nil)
+}
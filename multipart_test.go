@@ -0,0 +1,159 @@
+package raccoon_kv_client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// uploadServer implements multipart uploads, failing the parts in failures the given number of times.
+type uploadServer struct {
+	mu        sync.Mutex
+	failures  map[int]int
+	parts     map[int][]byte
+	active    int
+	maxActive int
+	value     []byte
+	completed bool
+	aborted   bool
+}
+
+func (s *uploadServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	switch {
+	case r.Method == "POST" && query.Has("uploads"):
+		_ = json.NewEncoder(w).Encode(uploadStart{UploadId: "upload-1"})
+	case r.Method == "PUT" && query.Has("part"):
+		number, _ := strconv.Atoi(query.Get("part"))
+		data, _ := io.ReadAll(r.Body)
+
+		s.mu.Lock()
+		s.active++
+		s.maxActive = max(s.maxActive, s.active)
+		s.mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.active--
+
+		if s.failures[number] > 0 {
+			s.failures[number]--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		s.parts[number] = data
+		w.Header().Set("etag", fmt.Sprintf("part-%d", number))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == "POST" && query.Has("complete"):
+		var completion uploadCompletion
+		_ = json.NewDecoder(r.Body).Decode(&completion)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.value = nil
+		for i, part := range completion.Parts {
+			if part.Part != i+1 || part.Version != fmt.Sprintf("part-%d", part.Part) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			s.value = append(s.value, s.parts[part.Part]...)
+		}
+
+		s.completed = true
+		_ = json.NewEncoder(w).Encode(uploadResult{Version: "1"})
+	case r.Method == "DELETE":
+		s.mu.Lock()
+		s.aborted = true
+		s.mu.Unlock()
+
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func TestPutLarge(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		failures map[int]int
+		parts    int
+		aborted  bool
+	}{
+		{"several parts", "0123456789", nil, 4, false},
+		{"exact multiple of the part size", "012345", nil, 2, false},
+		{"empty value", "", nil, 1, false},
+		{"part retried", "0123456789", map[int]int{2: 1}, 4, false},
+		{"part keeps failing", "0123456789", map[int]int{3: 2}, 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			upload := &uploadServer{failures: test.failures, parts: map[int][]byte{}}
+
+			server := httptest.NewServer(upload)
+			defer server.Close()
+
+			c, err := NewClient(server.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c.Backoff = ExponentialBackoff{Initial: time.Millisecond, Max: time.Millisecond}
+
+			opts := PutLargeOptions{PartSize: 3, Concurrency: 2, PartAttempts: 2}
+
+			err = c.PutLarge(context.Background(), "k", bytes.NewReader([]byte(test.value)), opts)
+
+			upload.mu.Lock()
+			defer upload.mu.Unlock()
+
+			if test.aborted {
+				if err == nil || !upload.aborted || upload.completed {
+					t.Errorf("PutLarge = %v, aborted = %t, completed = %t, want an aborted upload", err, upload.aborted, upload.completed)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(upload.value) != test.value || len(upload.parts) != test.parts {
+				t.Errorf("uploaded %q in %d parts, want %q in %d", upload.value, len(upload.parts), test.value, test.parts)
+			}
+
+			if upload.maxActive > opts.Concurrency {
+				t.Errorf("%d parts were uploaded at once, want at most %d", upload.maxActive, opts.Concurrency)
+			}
+		})
+	}
+}
+
+func TestPutLargeWithEncryption(t *testing.T) {
+	c, err := NewClient("http://localhost", WithEncryption(staticKeys{"k1": make([]byte, 32)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.PutLarge(context.Background(), "k", bytes.NewReader(nil), PutLargeOptions{}); !errors.Is(err, ErrEncryptedMultipart) {
+		t.Errorf("PutLarge = %v, want ErrEncryptedMultipart", err)
+	}
+}
@@ -0,0 +1,58 @@
+package raccoon_kv_client
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// WithNamespace prefixes every key the client sends to the server with namespace, e.g. "teams/payments/", and
+// strips it from the keys the server returns, so that several applications can share a server without seeing
+// each other's keys. Keys outside the namespace cannot be reached through the client.
+func WithNamespace(namespace string) Option {
+	return func(c *Client) {
+		if err := ValidateKey(strings.TrimSuffix(namespace, "/")); namespace != "" && err != nil {
+			c.err = fmt.Errorf("invalid namespace %q: %w", namespace, err)
+			return
+		}
+
+		c.namespace = namespace
+	}
+}
+
+// serverKey returns the key the server stores key under.
+func (c *Client) serverKey(key string) string {
+	return c.namespace + key
+}
+
+// clientKey returns the key the caller knows the server's key by.
+func (c *Client) clientKey(key string) string {
+	return strings.TrimPrefix(key, c.namespace)
+}
+
+// namespaceEnd returns the first key after every key in the client's namespace, for ranges that extend to the end
+// of the keyspace. It returns "" if the client has no namespace.
+func (c *Client) namespaceEnd() string {
+	end := []byte(c.namespace)
+
+	for len(end) > 0 {
+		if end[len(end)-1] < 0xff {
+			end[len(end)-1]++
+			return string(end)
+		}
+
+		end = end[:len(end)-1]
+	}
+
+	return ""
+}
+
+// namespaceOps returns ops with their keys moved into the client's namespace.
+func (c *Client) namespaceOps(ops []TxnOp) []TxnOp {
+	ops = slices.Clone(ops)
+	for i := range ops {
+		ops[i].Key = c.serverKey(ops[i].Key)
+	}
+
+	return ops
+}
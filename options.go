@@ -0,0 +1,196 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	defaultReadTimeout  = time.Second * 10
+	defaultWatchSeconds = 60
+)
+
+// Option configures a Client created by NewClient.
+type Option func(*Client)
+
+// NewClient returns a client for the raccoon-kv server at serverUrl, validating the URL up front. A
+// unix:///path/to/socket URL talks to a server listening on a Unix domain socket.
+func NewClient(serverUrl string, opts ...Option) (*Client, error) {
+	parsed, err := url.Parse(serverUrl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid server url: %w", err)
+	}
+
+	c := &Client{}
+
+	switch {
+	case parsed.Scheme == "unix":
+		if parsed.Path == "" {
+			return nil, fmt.Errorf("invalid server url %q: missing socket path", serverUrl)
+		}
+
+		c.Url = unixSocketUrl
+		c.unixSocket = parsed.Path
+	case parsed.Scheme != "http" && parsed.Scheme != "https":
+		return nil, fmt.Errorf("invalid server url %q: scheme must be http, https or unix", serverUrl)
+	case parsed.Host == "":
+		return nil, fmt.Errorf("invalid server url %q: missing host", serverUrl)
+	default:
+		c.Url = strings.TrimSuffix(serverUrl, "/")
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	return c, nil
+}
+
+// WithHTTPClient makes the client send every request, including watches, through httpClient. This is the place
+// to configure proxies, custom dialers or TLS settings. Timeouts are applied per request, so httpClient should not
+// set its own Timeout shorter than a watch long-poll.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTransport sends every request through transport instead of the client's own pooled transport. It is ignored
+// if WithHTTPClient is also given.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *Client) {
+		c.roundTripper = transport
+	}
+}
+
+// WithTLSConfig sets the TLS configuration of the client's transport, e.g. custom root CAs or a client
+// certificate for mutual TLS. TLSOptions.Config builds one from files. It has no effect together with
+// WithHTTPClient or WithTransport, which bring their own TLS settings.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(c *Client) {
+		c.tlsConfig = config
+	}
+}
+
+// WithProxy sends every request through the proxy at proxyUrl. Without it the client's transport honors the
+// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables. It is ignored if WithHTTPClient or WithTransport is
+// also given.
+func WithProxy(proxyUrl *url.URL) Option {
+	return func(c *Client) {
+		c.proxy = proxyUrl
+	}
+}
+
+// WithTimeout bounds every request other than watch long-polls; it is shorthand for WithReadTimeout and
+// WithWriteTimeout. By default reads time out after ten seconds and writes are only bounded by their context.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.readTimeout = timeout
+		c.writeTimeout = timeout
+	}
+}
+
+// WithReadTimeout bounds requests that only read from the store.
+func WithReadTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.readTimeout = timeout
+	}
+}
+
+// WithWriteTimeout bounds requests that modify the store.
+func WithWriteTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.writeTimeout = timeout
+	}
+}
+
+// WithWatchPollDuration sets how long the server may hold a watch long-poll open before answering that nothing
+// changed. It is sent in whole seconds and defaults to one minute.
+func WithWatchPollDuration(duration time.Duration) Option {
+	return func(c *Client) {
+		c.watchPollDuration = duration
+	}
+}
+
+// WithRetryBackoff sets the Backoff the client uses to pace retries. Individual watches can override it with
+// WithBackoff.
+func WithRetryBackoff(backoff Backoff) Option {
+	return func(c *Client) {
+		c.Backoff = backoff
+	}
+}
+
+// WithHeader adds a header to every request the client sends, e.g. a tenant ID or routing hint. Repeating it for
+// the same name adds further values.
+func WithHeader(name string, value string) Option {
+	return func(c *Client) {
+		if c.header == nil {
+			c.header = http.Header{}
+		}
+
+		c.header.Add(name, value)
+	}
+}
+
+// WithUserAgent appends product, e.g. "billing-service/2.3", to the client's default User-Agent of
+// "raccoon-kv-client/<version>" so server logs can attribute traffic to the application.
+func WithUserAgent(product string) Option {
+	return func(c *Client) {
+		c.userAgentSuffix = product
+	}
+}
+
+func (c *Client) readTimeoutFor(ctx context.Context) time.Duration {
+	if timeout := callOptions(ctx).timeout; timeout > 0 {
+		return timeout
+	}
+
+	if c.readTimeout > 0 {
+		return c.readTimeout
+	}
+
+	return defaultReadTimeout
+}
+
+// headerTimeoutFor returns how long a read waits for the server to respond, or zero if only its read timeout
+// bounds it. The adaptive timeout is learnt from the time to the response headers, so it bounds only this wait and
+// leaves the transfer of the value to the read timeout. It does not apply to watches or calls with a timeout.
+func (c *Client) headerTimeoutFor(ctx context.Context) time.Duration {
+	if c.adaptive == nil || callOptions(ctx).timeout > 0 || retriesDisabled(ctx) {
+		return 0
+	}
+
+	return c.adaptive.timeout(c.adaptive.reads)
+}
+
+// writeTimeoutFor returns zero if writes are not bounded.
+func (c *Client) writeTimeoutFor(ctx context.Context) time.Duration {
+	if timeout := callOptions(ctx).timeout; timeout > 0 {
+		return timeout
+	}
+
+	if c.adaptive != nil {
+		if timeout := c.adaptive.timeout(c.adaptive.writes); timeout > 0 {
+			return timeout
+		}
+	}
+
+	return c.writeTimeout
+}
+
+func (c *Client) watchSeconds() int {
+	if seconds := int(c.watchPollDuration / time.Second); seconds > 0 {
+		return seconds
+	}
+
+	return defaultWatchSeconds
+}
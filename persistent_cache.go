@@ -0,0 +1,269 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// CacheStore persists the last known value of keys for WithPersistentCache.
+type CacheStore interface {
+	// Load returns the stored value of key, or ok false if none is stored.
+	Load(key string) (data []byte, version string, ok bool, err error)
+	Store(key string, data []byte, version string) error
+	Delete(key string) error
+}
+
+// persistentCache records the values Get reads in a CacheStore.
+type persistentCache struct {
+	store CacheStore
+
+	mu sync.Mutex
+	// versions holds what the client last left in store for each key, so that unchanged values are not stored
+	// again and deleted keys are not deleted again.
+	versions map[string]storedVersion
+}
+
+// storedVersion is the version of a key's value in a CacheStore, or the absence of a value if stored is false.
+type storedVersion struct {
+	version string
+	stored  bool
+}
+
+// WithPersistentCache records every value Get reads in store, so that GetWithFallback can serve the last known
+// value of a key while the server is unreachable, even in a new process, e.g. when an application starts during an
+// outage. Writes and deletes made through the client remove the affected keys from store. With WithEncryption,
+// values are stored encrypted and only decrypted when they are served.
+func WithPersistentCache(store CacheStore) Option {
+	return func(c *Client) {
+		c.persistent = &persistentCache{store: store, versions: map[string]storedVersion{}}
+	}
+}
+
+// GetWithFallback is Get for a client with a cache: if the server cannot be reached or fails, it returns the last
+// known value of key instead, from the in-memory cache or else the persistent cache, with stale set. ErrNotFound
+// and client errors are returned as they are, since the server did answer.
+func (c *Client) GetWithFallback(ctx context.Context, key string) (data []byte, version string, stale bool, err error) {
+	data, version, err = c.Get(ctx, key)
+	if err == nil || !unreachable(ctx, err) {
+		return data, version, false, err
+	}
+
+	stored, storedVersion, ok := c.lastKnown(ctx, key)
+	if !ok {
+		return nil, "", false, err
+	}
+
+	c.log().Warn("server unavailable, serving last known value", slog.String("key", key), slog.String("err", err.Error()), requestIDAttr(err))
+
+	return stored, storedVersion, true, nil
+}
+
+// lastKnown returns the last value of key the client has seen, from its in-memory or persistent cache.
+func (c *Client) lastKnown(ctx context.Context, key string) ([]byte, string, bool) {
+	if c.cache != nil {
+		if entry, ok := c.cache.lookup(key); ok && !entry.missing {
+			return slices.Clone(entry.data), entry.version, true
+		}
+	}
+
+	if c.persistent != nil {
+		if sealed, version, ok, err := c.persistent.store.Load(key); err == nil && ok {
+			data, err := c.decrypt(ctx, key, sealed)
+			if err != nil {
+				c.log().Warn("failed to decrypt persistent cache entry", slog.String("key", key), slog.String("err", err.Error()))
+				return nil, "", false
+			}
+
+			return data, version, true
+		}
+	}
+
+	return nil, "", false
+}
+
+// unreachable reports whether err means that the server could not give an answer, as opposed to the caller
+// giving up or the server rejecting the request.
+func unreachable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, ErrNotFound) || errors.Is(err, ErrInvalidKey) {
+		return false
+	}
+
+	var responseErr *ResponseError
+	if errors.As(err, &responseErr) {
+		return responseErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	return true
+}
+
+// persist records the outcome of a Get in the persistent cache, if there is one: the value read, encrypted if the
+// client encrypts values, or the absence of a key the server reported missing.
+func (c *Client) persist(ctx context.Context, key string, data []byte, version string, err error) {
+	if c.persistent == nil {
+		return
+	}
+
+	switch {
+	case err == nil:
+		err = c.persistent.record(key, version, func() ([]byte, error) {
+			sealed, _, err := c.encrypt(ctx, key, data, nil)
+			return sealed, err
+		})
+	case errors.Is(err, ErrNotFound):
+		err = c.persistent.forget(key)
+	default:
+		return
+	}
+
+	if err != nil {
+		c.log().Warn("failed to update persistent cache", slog.String("key", key), slog.String("err", err.Error()))
+	}
+}
+
+// invalidate drops keys from the in-memory and persistent caches after they were written.
+func (c *Client) invalidate(keys ...string) {
+	c.cache.invalidate(keys...)
+
+	if c.persistent == nil {
+		return
+	}
+
+	for _, key := range keys {
+		if err := c.persistent.forget(key); err != nil {
+			c.log().Warn("failed to update persistent cache", slog.String("key", key), slog.String("err", err.Error()))
+		}
+	}
+}
+
+// record stores the value of key returned by value unless that version is already stored. Values without a version
+// cannot be told apart and are always stored.
+func (p *persistentCache) record(key string, version string, value func() ([]byte, error)) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if previous := p.versions[key]; previous.stored && version != "" && previous.version == version {
+		return nil
+	}
+
+	data, err := value()
+	if err != nil {
+		return err
+	}
+
+	if err := p.store.Store(key, data, version); err != nil {
+		return err
+	}
+
+	p.versions[key] = storedVersion{version: version, stored: true}
+
+	return nil
+}
+
+// forget deletes the stored value of key unless it is known not to be stored.
+func (p *persistentCache) forget(key string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if previous, ok := p.versions[key]; ok && !previous.stored {
+		return nil
+	}
+
+	if err := p.store.Delete(key); err != nil {
+		return err
+	}
+
+	p.versions[key] = storedVersion{}
+
+	return nil
+}
+
+// FileStore is a CacheStore keeping one file per key in a directory.
+type FileStore struct {
+	dir string
+}
+
+type storedValue struct {
+	Key     string `json:"key"`
+	Version string `json:"version"`
+	Value   []byte `json:"value"`
+}
+
+// NewFileStore returns a CacheStore that keeps its files in dir, creating it if needed.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	return &FileStore{dir: dir}, nil
+}
+
+func (s *FileStore) Load(key string) ([]byte, string, bool, error) {
+	contents, err := os.ReadFile(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, "", false, nil
+	}
+
+	if err != nil {
+		return nil, "", false, err
+	}
+
+	var stored storedValue
+	if err := json.Unmarshal(contents, &stored); err != nil {
+		return nil, "", false, err
+	}
+
+	if stored.Value == nil {
+		stored.Value = []byte{}
+	}
+
+	return stored.Value, stored.Version, true, nil
+}
+
+// Store writes the value to a temporary file first, so that a crash never leaves a partial value behind.
+func (s *FileStore) Store(key string, data []byte, version string) error {
+	contents, err := json.Marshal(storedValue{Key: key, Version: version, Value: data})
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(contents); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), s.path(key))
+}
+
+func (s *FileStore) Delete(key string) error {
+	err := os.Remove(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+// path names the file of key by its hash, since keys may contain characters that are not valid in file names.
+func (s *FileStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:]))
+}
@@ -0,0 +1,75 @@
+package raccoon_kv_client
+
+import (
+	"testing"
+)
+
+// countingStore is an in-memory CacheStore counting the operations it receives.
+type countingStore struct {
+	values  map[string][]byte
+	stores  int
+	deletes int
+}
+
+func (s *countingStore) Load(key string) ([]byte, string, bool, error) {
+	data, ok := s.values[key]
+	return data, "", ok, nil
+}
+
+func (s *countingStore) Store(key string, data []byte, _ string) error {
+	s.values[key] = data
+	s.stores++
+	return nil
+}
+
+func (s *countingStore) Delete(key string) error {
+	delete(s.values, key)
+	s.deletes++
+	return nil
+}
+
+func TestPersistentCacheRecord(t *testing.T) {
+	type step struct {
+		version string
+		forget  bool
+	}
+
+	tests := []struct {
+		name    string
+		steps   []step
+		stores  int
+		deletes int
+	}{
+		{"unchanged version", []step{{version: "1"}, {version: "1"}}, 1, 0},
+		{"new version", []step{{version: "1"}, {version: "2"}}, 2, 0},
+		{"no version", []step{{version: ""}, {version: ""}}, 2, 0},
+		{"unknown key", []step{{forget: true}}, 0, 1},
+		{"deleted twice", []step{{version: "1"}, {forget: true}, {forget: true}}, 1, 1},
+		{"stored again after delete", []step{{version: "1"}, {forget: true}, {version: "1"}}, 2, 1},
+		{"deleted without version", []step{{version: ""}, {forget: true}, {version: ""}, {forget: true}}, 2, 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := &countingStore{values: map[string][]byte{}}
+			cache := &persistentCache{store: store, versions: map[string]storedVersion{}}
+
+			for _, step := range test.steps {
+				var err error
+				if step.forget {
+					err = cache.forget("k")
+				} else {
+					err = cache.record("k", step.version, func() ([]byte, error) { return []byte("v"), nil })
+				}
+
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if store.stores != test.stores || store.deletes != test.deletes {
+				t.Errorf("stores = %d, deletes = %d, want %d and %d", store.stores, store.deletes, test.stores, test.deletes)
+			}
+		})
+	}
+}
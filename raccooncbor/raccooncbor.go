@@ -0,0 +1,28 @@
+// Package raccooncbor encodes raccoon-kv values as CBOR. Importing it registers Codec for "application/cbor".
+package raccooncbor
+
+import (
+	raccoon "github.com/RaccoonCorp/raccoon-kv-client"
+	"github.com/fxamacker/cbor/v2"
+)
+
+// Codec encodes values as CBOR.
+var Codec raccoon.Codec = codec{}
+
+func init() {
+	raccoon.RegisterCodec(Codec)
+}
+
+type codec struct{}
+
+func (codec) ContentType() string {
+	return "application/cbor"
+}
+
+func (codec) Marshal(v any) ([]byte, error) {
+	return cbor.Marshal(v)
+}
+
+func (codec) Unmarshal(data []byte, v any) error {
+	return cbor.Unmarshal(data, v)
+}
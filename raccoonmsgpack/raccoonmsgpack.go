@@ -0,0 +1,29 @@
+// Package raccoonmsgpack encodes raccoon-kv values as MessagePack. Importing it registers Codec for
+// "application/msgpack".
+package raccoonmsgpack
+
+import (
+	raccoon "github.com/RaccoonCorp/raccoon-kv-client"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Codec encodes values as MessagePack.
+var Codec raccoon.Codec = codec{}
+
+func init() {
+	raccoon.RegisterCodec(Codec)
+}
+
+type codec struct{}
+
+func (codec) ContentType() string {
+	return "application/msgpack"
+}
+
+func (codec) Marshal(v any) ([]byte, error) {
+	return msgpack.Marshal(v)
+}
+
+func (codec) Unmarshal(data []byte, v any) error {
+	return msgpack.Unmarshal(data, v)
+}
@@ -0,0 +1,77 @@
+// Package raccoonotel traces raccoon-kv client requests with OpenTelemetry.
+package raccoonotel
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	raccoon "github.com/RaccoonCorp/raccoon-kv-client"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/RaccoonCorp/raccoon-kv-client/raccoonotel"
+
+// Tracer records a span for every request of a client and propagates the trace context to the server using the
+// global propagator.
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// NewTracer returns a Tracer that creates its spans with provider.
+func NewTracer(provider trace.TracerProvider) *Tracer {
+	return &Tracer{tracer: provider.Tracer(instrumentationName, trace.WithInstrumentationVersion(raccoon.Version))}
+}
+
+// WithTracerProvider records an OpenTelemetry span for every request, including each long-poll of a watch, and
+// propagates the trace context to the server using the global propagator.
+func WithTracerProvider(provider trace.TracerProvider) raccoon.Option {
+	return raccoon.WithTracer(NewTracer(provider))
+}
+
+// Start starts a client span named after operation, e.g. "raccoon-kv get" or "raccoon-kv watch".
+func (t *Tracer) Start(request *http.Request, operation string) (context.Context, func(response *http.Response, err error)) {
+	attributes := []attribute.KeyValue{
+		attribute.String("http.request.method", request.Method),
+		attribute.String("url.full", request.URL.Redacted()),
+	}
+
+	_, key, found := strings.Cut(request.URL.Path, "/kv/")
+	if found {
+		attributes = append(attributes, attribute.String("raccoon.key", key))
+	}
+
+	ctx, span := t.tracer.Start(request.Context(), "raccoon-kv "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attributes...),
+	)
+
+	return ctx, func(response *http.Response, err error) {
+		defer span.End()
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return
+		}
+
+		span.SetAttributes(attribute.Int("http.response.status_code", response.StatusCode))
+
+		if version := response.Header.Get("etag"); version != "" {
+			span.SetAttributes(attribute.String("raccoon.version", version))
+		}
+
+		if response.StatusCode >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(response.StatusCode))
+		}
+	}
+}
+
+// Inject adds the trace context of the span in ctx to header.
+func (t *Tracer) Inject(ctx context.Context, header http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+}
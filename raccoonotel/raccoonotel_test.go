@@ -0,0 +1,44 @@
+package raccoonotel_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	raccoon "github.com/RaccoonCorp/raccoon-kv-client"
+	"github.com/RaccoonCorp/raccoon-kv-client/raccoonotel"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestTraceContextPropagation(t *testing.T) {
+	var traceparent string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+
+		w.Header().Set("etag", "1")
+		_, _ = w.Write([]byte("value"))
+	}))
+	defer server.Close()
+
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	c, err := raccoon.NewClient(server.URL, raccoonotel.WithTracerProvider(noop.NewTracerProvider()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{TraceID: trace.TraceID{1}, SpanID: trace.SpanID{2}, TraceFlags: trace.FlagsSampled})
+
+	if _, _, err := c.Get(trace.ContextWithSpanContext(context.Background(), spanContext), "k"); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "00-01000000000000000000000000000000-0200000000000000-01"; traceparent != want {
+		t.Errorf("traceparent = %q, want %q", traceparent, want)
+	}
+}
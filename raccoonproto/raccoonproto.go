@@ -0,0 +1,53 @@
+// Package raccoonproto encodes raccoon-kv values as protocol buffers. Importing it registers Codec for
+// "application/x-protobuf".
+package raccoonproto
+
+import (
+	"fmt"
+	"reflect"
+
+	raccoon "github.com/RaccoonCorp/raccoon-kv-client"
+	"google.golang.org/protobuf/proto"
+)
+
+// Codec encodes values, which must be proto.Message implementations, as protocol buffers.
+var Codec raccoon.Codec = codec{}
+
+func init() {
+	raccoon.RegisterCodec(Codec)
+}
+
+type codec struct{}
+
+func (codec) ContentType() string {
+	return "application/x-protobuf"
+}
+
+func (codec) Marshal(v any) ([]byte, error) {
+	message, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a proto.Message", v)
+	}
+
+	return proto.Marshal(message)
+}
+
+// Unmarshal decodes into a proto.Message or, as Typed does for a message type T, into a pointer to a message
+// pointer, allocating the message if needed.
+func (codec) Unmarshal(data []byte, v any) error {
+	message, ok := v.(proto.Message)
+
+	if target := reflect.ValueOf(v); !ok && target.Kind() == reflect.Pointer && target.Elem().Kind() == reflect.Pointer {
+		if target.Elem().IsNil() {
+			target.Elem().Set(reflect.New(target.Elem().Type().Elem()))
+		}
+
+		message, ok = target.Elem().Interface().(proto.Message)
+	}
+
+	if !ok {
+		return fmt.Errorf("%T is not a proto.Message", v)
+	}
+
+	return proto.Unmarshal(data, message)
+}
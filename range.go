@@ -0,0 +1,112 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ErrRangeNotSatisfiable is returned by GetRangeBytes when offset lies beyond the end of the value.
+var ErrRangeNotSatisfiable = errors.New("range not satisfiable")
+
+// GetRangeBytes returns length bytes of the value of key starting at offset, along with the value's version,
+// without downloading the rest of the value. A length of zero or less reads to the end of the value, and fewer
+// bytes are returned if the value ends first. Encrypted values can only be decrypted as a whole, so they are
+// downloaded in full and sliced locally. It returns ErrNotFound if the key does not exist.
+func (c *Client) GetRangeBytes(ctx context.Context, key string, offset int64, length int64) (data []byte, version string, err error) {
+	if offset < 0 {
+		return nil, "", fmt.Errorf("offset must not be negative, got %d", offset)
+	}
+
+	if c.keys != nil {
+		data, version, err := c.Get(ctx, key)
+		if err != nil {
+			return nil, version, err
+		}
+
+		data, err = sliceRange(data, offset, length)
+		return data, version, err
+	}
+
+	keyUrl, err := c.keyUrl(key, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	ctx, cancel := withTimeout(ctx, c.readTimeoutFor(ctx))
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, "GET", keyUrl, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	request.Header.Set("range", rangeHeader(offset, length))
+	request.Header.Set("accept-encoding", "identity")
+
+	response, err := c.doRead(request)
+	if err != nil {
+		return nil, "", err
+	}
+	defer drainAndClose(response.Body)
+
+	version = response.Header.Get("etag")
+
+	switch response.StatusCode {
+	case http.StatusPartialContent:
+	case http.StatusOK:
+		// The server ignored the range and sent the whole value.
+		data, err = c.readValue(ctx, key, response)
+		if err != nil {
+			return nil, "", err
+		}
+
+		data, err = sliceRange(data, offset, length)
+		return data, version, err
+	case http.StatusRequestedRangeNotSatisfiable:
+		return nil, version, ErrRangeNotSatisfiable
+	case http.StatusNotFound:
+		return nil, version, ErrNotFound
+	default:
+		return nil, "", newResponseError(response)
+	}
+
+	if c.maxValueSize > 0 && response.ContentLength > c.maxValueSize {
+		return nil, "", &SizeLimitError{Limit: c.maxValueSize, Size: response.ContentLength}
+	}
+
+	data, err = io.ReadAll(limitReader(response.Body, c.maxValueSize))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return data, version, nil
+}
+
+// rangeHeader formats a Range header for length bytes from offset, or for the rest of the value if length is not
+// positive.
+func rangeHeader(offset int64, length int64) string {
+	if length <= 0 {
+		return fmt.Sprintf("bytes=%d-", offset)
+	}
+
+	return fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)
+}
+
+// sliceRange returns the part of data the server would have sent for the range, or ErrRangeNotSatisfiable as
+// the server would have.
+func sliceRange(data []byte, offset int64, length int64) ([]byte, error) {
+	if offset > 0 && offset >= int64(len(data)) {
+		return nil, ErrRangeNotSatisfiable
+	}
+
+	data = data[offset:]
+
+	if length > 0 && length < int64(len(data)) {
+		data = data[:length]
+	}
+
+	return data, nil
+}
@@ -0,0 +1,97 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is returned when the client's rate limit would delay a request past its context's deadline.
+var ErrRateLimited = errors.New("rate limited")
+
+// WithRateLimit limits the client to rps requests per second with bursts of up to burst requests. Requests beyond
+// the limit wait for their turn, or fail with ErrRateLimited if their context's deadline would pass first. rps must
+// be positive and burst at least one.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(c *Client) {
+		if !(rps > 0) || math.IsInf(rps, 1) {
+			c.err = fmt.Errorf("invalid rate limit: rps must be positive and finite, got %v", rps)
+			return
+		}
+
+		if burst < 1 {
+			c.err = fmt.Errorf("invalid rate limit: burst must be at least 1, got %d", burst)
+			return
+		}
+
+		c.limiter = &rateLimiter{
+			rate:   rps,
+			burst:  float64(burst),
+			tokens: float64(burst),
+			last:   time.Now(),
+		}
+	}
+}
+
+// rateLimiter is a token bucket holding up to burst tokens, refilled at rate tokens per second.
+type rateLimiter struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// wait blocks until a token is available for the request carrying ctx.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	delay := l.reserve()
+	if delay <= 0 {
+		return nil
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		l.release()
+		return ErrRateLimited
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		l.release()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// reserve takes a token, possibly going into debt, and returns how long the caller has to wait until the debt is
+// paid off.
+func (l *rateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.tokens--
+
+	if l.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// release returns a token taken by a request that is not sent after all.
+func (l *rateLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tokens = min(l.burst, l.tokens+1)
+}
@@ -0,0 +1,67 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestWithRateLimitValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		rps   float64
+		burst int
+		valid bool
+	}{
+		{"valid", 10, 1, true},
+		{"zero rps", 0, 1, false},
+		{"negative rps", -1, 1, false},
+		{"NaN rps", math.NaN(), 1, false},
+		{"infinite rps", math.Inf(1), 1, false},
+		{"zero burst", 10, 0, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewClient("http://localhost", WithRateLimit(test.rps, test.burst))
+			if (err == nil) != test.valid {
+				t.Errorf("NewClient = %v, want valid = %t", err, test.valid)
+			}
+		})
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	c, err := NewClient("http://localhost", WithRateLimit(20, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+
+	for range 3 {
+		if err := c.limiter.wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("three requests with a burst of two took %s, want the third to wait about 50ms", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := c.limiter.wait(ctx); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("wait past the deadline = %v, want ErrRateLimited", err)
+	}
+
+	// The request that gave up returned its token, so the bucket refills as if it had never been made.
+	time.Sleep(50 * time.Millisecond)
+
+	if delay := c.limiter.reserve(); delay > 0 {
+		t.Errorf("reserve after the refill = %s, want no wait", delay)
+	}
+}
@@ -0,0 +1,89 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+type requestIDKey struct{}
+
+// RequestError is returned when a request got no response from the server, e.g. because the connection failed or
+// the request timed out. It carries the ID the request was sent with, so that the failure can be found in the logs
+// of proxies and the server.
+type RequestError struct {
+	RequestID string
+	Err       error
+}
+
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("%s (request id %s)", e.Err, e.RequestID)
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// RequestID returns the ID of the request err is about, from a *RequestError or a *ResponseError, or "" if err
+// names no request.
+func RequestID(err error) string {
+	var requestErr *RequestError
+	if errors.As(err, &requestErr) {
+		return requestErr.RequestID
+	}
+
+	var responseErr *ResponseError
+	if errors.As(err, &responseErr) {
+		return responseErr.RequestID
+	}
+
+	return ""
+}
+
+// ContextWithRequestID returns a context whose operations send id as their X-Request-ID instead of a generated
+// one, e.g. to reuse the ID of an incoming request so that client and server logs can be correlated.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// setRequestID gives request the ID from its context or a new one, unless it already has one. Retries of the
+// request keep its ID.
+func setRequestID(request *http.Request) {
+	if request.Header.Get("x-request-id") != "" {
+		return
+	}
+
+	id, _ := request.Context().Value(requestIDKey{}).(string)
+	if id == "" {
+		var nonce [16]byte
+		_, _ = rand.Read(nonce[:])
+
+		id = hex.EncodeToString(nonce[:])
+	}
+
+	request.Header.Set("x-request-id", id)
+}
+
+// requestIDAttr returns the log attribute naming the request err is about, or an empty attribute, which handlers
+// omit, if err names none.
+func requestIDAttr(err error) slog.Attr {
+	id := RequestID(err)
+	if id == "" {
+		return slog.Attr{}
+	}
+
+	return slog.String("request_id", id)
+}
+
+// responseRequestIDAttr returns the log attribute naming the request response answers.
+func responseRequestIDAttr(response *http.Response) slog.Attr {
+	if response.Request == nil {
+		return slog.Attr{}
+	}
+
+	return slog.String("request_id", response.Request.Header.Get("x-request-id"))
+}
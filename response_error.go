@@ -0,0 +1,65 @@
+package raccoon_kv_client
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxErrorBody bounds how much of an error response's body a ResponseError keeps.
+const maxErrorBody = 512
+
+// ResponseError is returned when the server answers with a status code the operation does not expect. It wraps
+// RequestFailedErr.
+type ResponseError struct {
+	StatusCode int
+	Method     string
+	URL        string
+	// Body holds the start of the response body, which usually explains the failure.
+	Body string
+	// RequestID is the ID the server reported for the request, or else the X-Request-ID the client sent.
+	RequestID string
+}
+
+func (e *ResponseError) Error() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "unexpected status code %d from %s %s", e.StatusCode, e.Method, e.URL)
+
+	if e.RequestID != "" {
+		fmt.Fprintf(&b, " (request id %s)", e.RequestID)
+	}
+
+	if e.Body != "" {
+		fmt.Fprintf(&b, ": %s", e.Body)
+	}
+
+	return b.String()
+}
+
+func (e *ResponseError) Unwrap() error {
+	return RequestFailedErr
+}
+
+// newResponseError describes the unexpected response, reading the start of its body.
+func newResponseError(response *http.Response) *ResponseError {
+	err := &ResponseError{
+		StatusCode: response.StatusCode,
+		RequestID:  response.Header.Get("x-request-id"),
+	}
+
+	if response.Request != nil {
+		err.Method = response.Request.Method
+		err.URL = response.Request.URL.Redacted()
+
+		if err.RequestID == "" {
+			err.RequestID = response.Request.Header.Get("x-request-id")
+		}
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(response.Body, maxErrorBody))
+	err.Body = strings.TrimSpace(string(body))
+
+	return err
+}
@@ -0,0 +1,113 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ResumeDownloads lets GetReader resume an interrupted transfer up to maxResumes times, asking the server with a
+// Range request for the rest of the value from where the transfer stopped. The resumed request is made
+// conditional on the version first read; if the value has changed since, reading fails with ErrConflict.
+func ResumeDownloads(maxResumes int) CallOption {
+	return func(config *callConfig) {
+		config.resumes = maxResumes
+	}
+}
+
+// resumingBody is the body of a GetReader response that re-requests the rest of the value when the transfer is
+// interrupted.
+type resumingBody struct {
+	ctx        context.Context
+	client     *Client
+	url        string
+	version    string
+	offset     int64
+	resumes    int
+	maxResumes int
+
+	body   io.ReadCloser
+	cancel context.CancelFunc
+	// err is the error resuming failed with, returned by all further reads.
+	err error
+}
+
+func (b *resumingBody) Read(p []byte) (int, error) {
+	for {
+		if b.err != nil {
+			return 0, b.err
+		}
+
+		n, err := b.body.Read(p)
+		b.offset += int64(n)
+
+		if err == nil || errors.Is(err, io.EOF) || b.ctx.Err() != nil || b.resumes >= b.maxResumes {
+			return n, err
+		}
+
+		if n > 0 {
+			// Deliver what arrived; the next read runs into the error again and resumes.
+			return n, nil
+		}
+
+		b.resumes++
+		b.err = b.resume()
+	}
+}
+
+// resume replaces the interrupted body with the rest of the value, once the client's backoff has elapsed.
+func (b *resumingBody) resume() error {
+	_ = b.body.Close()
+	b.cancel()
+
+	backoff := b.client.Backoff
+	if backoff == nil {
+		backoff = DefaultBackoff
+	}
+
+	select {
+	case <-b.ctx.Done():
+		return b.ctx.Err()
+	case <-time.After(backoff.Delay(b.resumes)):
+	}
+
+	header := http.Header{}
+	header.Set("range", rangeHeader(b.offset, 0))
+	header.Set("if-match", b.version)
+	header.Set("accept-encoding", "identity")
+
+	b.body, b.cancel = http.NoBody, func() {}
+
+	response, cancel, err := b.client.openValue(b.ctx, b.url, header)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case response.StatusCode == http.StatusPreconditionFailed:
+		err = fmt.Errorf("%w: value changed while it was downloaded", ErrConflict)
+	case response.StatusCode != http.StatusPartialContent:
+		err = newResponseError(response)
+	case !strings.HasPrefix(response.Header.Get("content-range"), fmt.Sprintf("bytes %d-", b.offset)):
+		err = fmt.Errorf("server resumed at %q instead of offset %d", response.Header.Get("content-range"), b.offset)
+	default:
+		b.body, b.cancel = response.Body, cancel
+		return nil
+	}
+
+	drainAndClose(response.Body)
+	cancel()
+
+	return err
+}
+
+func (b *resumingBody) Close() error {
+	err := b.body.Close()
+	b.cancel()
+
+	return err
+}
@@ -0,0 +1,53 @@
+package raccoon_kv_client
+
+import (
+	"net/http"
+	"sync"
+)
+
+// RetryBudget bounds the retries of all requests sharing it relative to the requests that succeed, so that
+// during an outage retries cannot multiply the load on the server. A successful request earns a fraction of a retry
+// and every retry spends one.
+type RetryBudget struct {
+	ratio float64
+	max   float64
+
+	mu     sync.Mutex
+	tokens float64
+}
+
+// NewRetryBudget returns a budget that earns ratio retries per successful request, e.g. 0.1 for one retry per ten
+// successes, and saves up at most maxRetries. It starts out full.
+func NewRetryBudget(ratio float64, maxRetries int) *RetryBudget {
+	return &RetryBudget{
+		ratio:  ratio,
+		max:    float64(maxRetries),
+		tokens: float64(maxRetries),
+	}
+}
+
+// deposit credits the budget if response is a success.
+func (b *RetryBudget) deposit(response *http.Response, err error) {
+	if err != nil || response.StatusCode >= http.StatusInternalServerError {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = min(b.max, b.tokens+b.ratio)
+}
+
+// withdraw spends one retry, reporting false if the budget is exhausted.
+func (b *RetryBudget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+
+	return true
+}
@@ -0,0 +1,149 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// DefaultRetryableStatus lists the status codes a RetryPolicy retries if it does not name its own.
+var DefaultRetryableStatus = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryPolicy controls how requests other than watches are retried after transient failures. Watches retry on
+// their own and are not affected.
+//
+// Reads, and writes with an idempotency key, are retried after network errors and retryable status codes. Other
+// writes are only retried when the server cannot have applied them: when the connection could not be established,
+// or when it answered 429 Too Many Requests or 503 Service Unavailable.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first. Values below two disable retries.
+	MaxAttempts int
+	// RetryableStatus lists the status codes that are retried. DefaultRetryableStatus is used if it is nil.
+	RetryableStatus []int
+	// Backoff paces the attempts. The client's Backoff is used if it is nil.
+	Backoff Backoff
+	// Budget, if set, caps the retries across all requests of the client.
+	Budget *RetryBudget
+}
+
+type noRetriesKey struct{}
+
+// WithRetryPolicy retries requests other than watches according to policy.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retryPolicy = &policy
+	}
+}
+
+// withoutRetries marks ctx as belonging to a watch, whose requests are retried by the watch itself.
+func withoutRetries(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noRetriesKey{}, true)
+}
+
+func retriesDisabled(ctx context.Context) bool {
+	disabled, _ := ctx.Value(noRetriesKey{}).(bool)
+	return disabled
+}
+
+// doWithRetries sends request, sending it again according to the client's retry policy while it fails with a
+// retryable error.
+func (c *Client) doWithRetries(request *http.Request) (*http.Response, error) {
+	policy := c.retryPolicy
+
+	backoff := policy.Backoff
+	if backoff == nil {
+		backoff = c.Backoff
+	}
+	if backoff == nil {
+		backoff = DefaultBackoff
+	}
+
+	attempt := request
+
+	for failures := 1; ; failures++ {
+		response, err := c.attempt(attempt)
+
+		if policy.Budget != nil {
+			policy.Budget.deposit(response, err)
+		}
+
+		if failures >= policy.MaxAttempts || !policy.retryable(request, response, err) {
+			return response, err
+		}
+
+		if policy.Budget != nil && !policy.Budget.withdraw() {
+			return response, err
+		}
+
+		if c.hooks.OnRetry != nil {
+			c.hooks.OnRetry(request, failures+1, response, err)
+		}
+
+		if err == nil {
+			drainAndClose(response.Body)
+		}
+
+		select {
+		case <-request.Context().Done():
+			return nil, request.Context().Err()
+		case <-time.After(backoff.Delay(failures)):
+		}
+
+		attempt = request.Clone(request.Context())
+
+		if request.GetBody != nil {
+			attempt.Body, err = request.GetBody()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// retryable reports whether request may be sent again after failing with response or err.
+func (p *RetryPolicy) retryable(request *http.Request, response *http.Response, err error) bool {
+	if request.Context().Err() != nil || (request.Body != nil && request.GetBody == nil) {
+		return false
+	}
+
+	if errors.Is(err, ErrCircuitOpen) || errors.Is(err, ErrRateLimited) {
+		return false
+	}
+
+	read := idempotent(request)
+
+	if err != nil {
+		return read || dialFailed(err)
+	}
+
+	statuses := p.RetryableStatus
+	if statuses == nil {
+		statuses = DefaultRetryableStatus
+	}
+
+	if !slices.Contains(statuses, response.StatusCode) {
+		return false
+	}
+
+	return read || response.StatusCode == http.StatusTooManyRequests || response.StatusCode == http.StatusServiceUnavailable
+}
+
+// idempotent reports whether request may be applied twice without harm: it is a read or carries an idempotency key.
+func idempotent(request *http.Request) bool {
+	return request.Method == "GET" || request.Method == "HEAD" || request.Header.Get("idempotency-key") != ""
+}
+
+// dialFailed reports whether err means the connection could not be established, so the server never saw the
+// request.
+func dialFailed(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
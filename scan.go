@@ -0,0 +1,146 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/url"
+)
+
+// KeyValue is a key with its value and version, as produced by Scan.
+type KeyValue struct {
+	Key     string `json:"key"`
+	Version string `json:"version"`
+	Value   []byte `json:"value"`
+}
+
+type scanPage struct {
+	Keys   []KeyValue `json:"keys"`
+	Cursor string     `json:"cursor"`
+}
+
+// Scanner lazily walks a set of keys page by page. Call Next until it returns false, then check Err.
+type Scanner struct {
+	client *Client
+	ctx    context.Context
+	query  url.Values
+
+	page    []KeyValue
+	current KeyValue
+	last    bool
+	err     error
+}
+
+// Scan returns a Scanner over all keys starting with prefix, fetching values one server page at a time.
+func (c *Client) Scan(ctx context.Context, prefix string) *Scanner {
+	query := url.Values{}
+	query.Set("prefix", c.serverKey(prefix))
+
+	return c.newScanner(ctx, query)
+}
+
+// ScanSeq is the range-over-func form of Scan. A failure ends the sequence with a final error.
+func (c *Client) ScanSeq(ctx context.Context, prefix string) iter.Seq2[KeyValue, error] {
+	return func(yield func(KeyValue, error) bool) {
+		scanner := c.Scan(ctx, prefix)
+		for scanner.Next() {
+			if !yield(scanner.Entry(), nil) {
+				return
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			yield(KeyValue{}, err)
+		}
+	}
+}
+
+// GetRange returns all entries whose keys fall in the half-open lexicographic range [startKey, endKey). An empty
+// endKey extends the range to the end of the keyspace.
+func (c *Client) GetRange(ctx context.Context, startKey string, endKey string) ([]KeyValue, error) {
+	query := url.Values{}
+	query.Set("start", c.serverKey(startKey))
+
+	if endKey != "" {
+		query.Set("end", c.serverKey(endKey))
+	} else if end := c.namespaceEnd(); end != "" {
+		query.Set("end", end)
+	}
+
+	scanner := c.newScanner(ctx, query)
+
+	var entries []KeyValue
+	for scanner.Next() {
+		entries = append(entries, scanner.Entry())
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (c *Client) newScanner(ctx context.Context, query url.Values) *Scanner {
+	query.Set("values", "true")
+
+	return &Scanner{
+		client: c,
+		ctx:    ctx,
+		query:  query,
+	}
+}
+
+// Next advances to the next entry, fetching another page when the current one is exhausted.
+func (s *Scanner) Next() bool {
+	for len(s.page) == 0 {
+		if s.last || s.err != nil {
+			return false
+		}
+
+		s.fetch()
+	}
+
+	s.current = s.page[0]
+	s.page = s.page[1:]
+
+	return true
+}
+
+// Entry returns the entry Next advanced to.
+func (s *Scanner) Entry() KeyValue {
+	return s.current
+}
+
+// Err returns the error that stopped the scan, if any.
+func (s *Scanner) Err() error {
+	return s.err
+}
+
+func (s *Scanner) fetch() {
+	var page scanPage
+	if err := s.client.getJSON(s.ctx, fmt.Sprintf("%s/kv?%s", s.client.Url, s.query.Encode()), &page); err != nil {
+		s.err = err
+		return
+	}
+
+	for i := range page.Keys {
+		page.Keys[i].Key = s.client.clientKey(page.Keys[i].Key)
+
+		value, err := s.client.decrypt(s.ctx, page.Keys[i].Key, page.Keys[i].Value)
+		if err != nil {
+			s.err = fmt.Errorf("%s: %w", page.Keys[i].Key, err)
+			return
+		}
+
+		page.Keys[i].Value = value
+	}
+
+	s.page = page.Keys
+
+	if page.Cursor == "" {
+		s.last = true
+	} else {
+		s.query.Set("cursor", page.Cursor)
+	}
+}
@@ -0,0 +1,24 @@
+package raccoon_kv_client
+
+import "net/http"
+
+// RequestSigner signs outgoing requests, e.g. by hashing the method, path, body and a timestamp into a signature
+// header. Sign runs after credentials and all other headers have been set, so it sees the request as it is sent.
+// The body can be read through request.GetBody without consuming it.
+type RequestSigner interface {
+	Sign(request *http.Request) error
+}
+
+// RequestSignerFunc adapts a function to a RequestSigner.
+type RequestSignerFunc func(request *http.Request) error
+
+func (f RequestSignerFunc) Sign(request *http.Request) error {
+	return f(request)
+}
+
+// WithRequestSigner signs every request, including watches and retries, with signer.
+func WithRequestSigner(signer RequestSigner) Option {
+	return func(c *Client) {
+		c.signer = signer
+	}
+}
@@ -0,0 +1,91 @@
+package raccoon_kv_client
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrValueTooLarge is wrapped by every SizeLimitError.
+var ErrValueTooLarge = errors.New("value too large")
+
+// SizeLimitError is returned when a value exceeds the limit set with WithMaxValueSize or WithMaxPutSize. It wraps
+// ErrValueTooLarge.
+type SizeLimitError struct {
+	Limit int64
+	// Size is the size of the value if it is known, and -1 if it was cut off at the limit.
+	Size int64
+}
+
+func (e *SizeLimitError) Error() string {
+	if e.Size < 0 {
+		return fmt.Sprintf("value exceeds the limit of %d bytes", e.Limit)
+	}
+
+	return fmt.Sprintf("value of %d bytes exceeds the limit of %d bytes", e.Size, e.Limit)
+}
+
+func (e *SizeLimitError) Unwrap() error {
+	return ErrValueTooLarge
+}
+
+// WithMaxValueSize fails reads of values larger than limit bytes with a *SizeLimitError instead of reading them
+// into memory. Values announced as larger are rejected before their body is read, and the check applies to the
+// decompressed value. Values streamed with GetReader are not held in memory and are not limited.
+func WithMaxValueSize(limit int64) Option {
+	return func(c *Client) {
+		c.maxValueSize = limit
+	}
+}
+
+// WithMaxPutSize fails writes of values larger than limit bytes with a *SizeLimitError. Values of a known size are
+// rejected before anything is sent; values streamed from a reader are cut off once they exceed the limit.
+func WithMaxPutSize(limit int64) Option {
+	return func(c *Client) {
+		c.maxPutSize = limit
+	}
+}
+
+// checkPutSize rejects a value of size bytes if it exceeds the client's limit.
+func (c *Client) checkPutSize(size int64) error {
+	if c.maxPutSize > 0 && size > c.maxPutSize {
+		return &SizeLimitError{Limit: c.maxPutSize, Size: size}
+	}
+
+	return nil
+}
+
+// limitedReader fails with a *SizeLimitError once more than limit bytes have been read from it.
+type limitedReader struct {
+	io.Reader
+	limit int64
+	read  int64
+}
+
+// limitReader returns r limited to limit bytes, or r itself if limit is not positive.
+func limitReader(r io.Reader, limit int64) io.Reader {
+	if limit <= 0 {
+		return r
+	}
+
+	return &limitedReader{Reader: r, limit: limit}
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	if r.read > r.limit {
+		return 0, &SizeLimitError{Limit: r.limit, Size: -1}
+	}
+
+	if remaining := r.limit + 1 - r.read; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+
+	n, err := r.Reader.Read(p)
+	r.read += int64(n)
+
+	if r.read > r.limit {
+		return n - int(r.read-r.limit), &SizeLimitError{Limit: r.limit, Size: -1}
+	}
+
+	return n, err
+}
@@ -0,0 +1,113 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrNoCache is returned by operations on the cache of a client created without one.
+var ErrNoCache = errors.New("client has no cache")
+
+type snapshot struct {
+	SavedAt time.Time     `json:"saved_at"`
+	Entries []storedValue `json:"entries"`
+}
+
+// SaveSnapshot writes the values in the client's cache to the file at path, replacing it atomically, so that a
+// later process can warm-start from them with LoadSnapshot. With WithEncryption, the values are written encrypted.
+func (c *Client) SaveSnapshot(path string) error {
+	if c.cache == nil {
+		return ErrNoCache
+	}
+
+	entries := c.cache.values()
+
+	for i := range entries {
+		sealed, _, err := c.encrypt(context.Background(), entries[i].Key, entries[i].Value, nil)
+		if err != nil {
+			return err
+		}
+
+		entries[i].Value = sealed
+	}
+
+	contents, err := json.Marshal(snapshot{SavedAt: time.Now(), Entries: entries})
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), ".snapshot-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(contents); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
+
+// LoadSnapshot fills the client's cache with the values saved by SaveSnapshot. Loaded values are revalidated
+// with the server on their first Get, which costs no download if they are still current, and GetWithFallback
+// serves them while the server is unreachable.
+func (c *Client) LoadSnapshot(path string) error {
+	if c.cache == nil {
+		return ErrNoCache
+	}
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var saved snapshot
+	if err := json.Unmarshal(contents, &saved); err != nil {
+		return err
+	}
+
+	generation := c.cache.currentGeneration()
+
+	for _, entry := range saved.Entries {
+		entry.Value, err = c.decrypt(context.Background(), entry.Key, entry.Value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", entry.Key, err)
+		}
+
+		if entry.Value == nil {
+			entry.Value = []byte{}
+		}
+
+		c.cache.store(entry.Key, &cacheEntry{data: entry.Value, version: entry.Version}, generation)
+		c.watchCached(entry.Key)
+	}
+
+	return nil
+}
+
+// values returns the cached values, leaving out keys cached as missing.
+func (vc *valueCache) values() []storedValue {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	values := make([]storedValue, 0, len(vc.entries))
+
+	for key, entry := range vc.entries {
+		if !entry.missing {
+			values = append(values, storedValue{Key: key, Version: entry.version, Value: entry.data})
+		}
+	}
+
+	return values
+}
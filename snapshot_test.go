@@ -0,0 +1,95 @@
+package raccoon_kv_client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSnapshot(t *testing.T) {
+	server := newCacheServer()
+	server.set("a", "value a")
+	server.set("b", "value b")
+
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+
+	saver := cachedClient(t, server, WithNegativeCache(time.Minute))
+	getValue(t, saver, "a")
+	getValue(t, saver, "b")
+	_, _, _ = saver.Get(context.Background(), "missing")
+
+	if err := saver.SaveSnapshot(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loader := cachedClient(t, server)
+	if err := loader.LoadSnapshot(path); err != nil {
+		t.Fatal(err)
+	}
+
+	if stats := loader.CacheStats(); stats.Entries != 2 {
+		t.Errorf("loaded %d entries, want the 2 values without the missing key", stats.Entries)
+	}
+
+	_, downloads := server.counts()
+
+	if got := getValue(t, loader, "a"); got != "value a" {
+		t.Errorf("Get = %q, want %q", got, "value a")
+	}
+
+	if _, after := server.counts(); after != downloads {
+		t.Errorf("downloads = %d, want the loaded value revalidated without downloading it", after-downloads)
+	}
+}
+
+func TestSnapshotEncryption(t *testing.T) {
+	server := newCacheServer()
+
+	keys := staticKeys{"k1": make([]byte, 32)}
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+
+	saver := cachedClient(t, server, WithEncryption(keys))
+
+	if err := saver.Put(context.Background(), "k", []byte("secret value")); err != nil {
+		t.Fatal(err)
+	}
+
+	getValue(t, saver, "k")
+
+	if err := saver.SaveSnapshot(path); err != nil {
+		t.Fatal(err)
+	}
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Contains(contents, []byte("secret value")) || bytes.Contains(contents, []byte("c2VjcmV0IHZhbHVl")) {
+		t.Error("snapshot contains the plaintext value")
+	}
+
+	if err := cachedClient(t, server, WithEncryption(keys)).LoadSnapshot(path); err != nil {
+		t.Errorf("LoadSnapshot with the same keys = %v, want nil", err)
+	}
+
+	other := cachedClient(t, server, WithEncryption(staticKeys{"k1": bytes.Repeat([]byte{1}, 32)}))
+	if err := other.LoadSnapshot(path); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("LoadSnapshot with other keys = %v, want ErrDecrypt", err)
+	}
+}
+
+func TestSnapshotWithoutCache(t *testing.T) {
+	c, err := NewClient("http://localhost")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.SaveSnapshot(filepath.Join(t.TempDir(), "snapshot.json")); !errors.Is(err, ErrNoCache) {
+		t.Errorf("SaveSnapshot = %v, want ErrNoCache", err)
+	}
+}
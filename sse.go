@@ -0,0 +1,168 @@
+package raccoon_kv_client
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+var errStreamUnsupported = errors.New("server does not support event streams")
+
+var errStreamClosed = errors.New("event stream closed without delivering events")
+
+// stream follows requestUrl, the value of key, as a Server-Sent Events stream until ctx ends, reconnecting with
+// the last seen version whenever the connection drops. Each event carries the version as its id and is either a
+// "put", whose data is the base64 encoded value, or a "delete". It returns errStreamUnsupported if the very first
+// connection is not answered with an event stream.
+func (c *Client) stream(ctx context.Context, key string, requestUrl string, config *watchConfig, onChange func(data []byte, version string) error) error {
+	ctx = withoutRetries(ctx)
+
+	lastVersion := config.startVersion
+	if config.initial == initialForce {
+		lastVersion = ""
+	}
+
+	connected := false
+
+	return retry(ctx, config, func() error {
+		attemptCtx, cancel := config.interrupter.attempt(ctx)
+		defer cancel()
+
+		request, err := http.NewRequestWithContext(attemptCtx, "GET", requestUrl, nil)
+		if err != nil {
+			return err
+		}
+
+		request.Header.Set("accept", "text/event-stream")
+
+		if lastVersion != "" {
+			request.Header.Set("last-event-id", lastVersion)
+		}
+
+		response, err := c.do(request)
+		if err != nil {
+			return err
+		}
+		defer response.Body.Close()
+
+		mediaType, _, _ := mime.ParseMediaType(response.Header.Get("content-type"))
+		if response.StatusCode != http.StatusOK || mediaType != "text/event-stream" {
+			if !connected {
+				return errStreamUnsupported
+			}
+
+			return newResponseError(response)
+		}
+
+		connected = true
+		received := false
+
+		config.stats.succeeded()
+
+		err = readEvents(response.Body, func(name string, id string, data string) error {
+			received = true
+
+			config.stats.succeeded()
+
+			if id == "" || id == lastVersion {
+				return nil
+			}
+
+			var value []byte
+
+			switch name {
+			case "put":
+				value, err = base64.StdEncoding.DecodeString(data)
+				if err != nil {
+					return fmt.Errorf("malformed event data: %w", err)
+				}
+
+				if value == nil {
+					value = []byte{}
+				}
+
+				value, err = c.decrypt(ctx, key, value)
+				if err != nil {
+					return err
+				}
+			case "delete":
+			default:
+				return nil
+			}
+
+			if err := onChange(value, id); err != nil {
+				return err
+			}
+
+			lastVersion = id
+
+			return nil
+		})
+		if attemptCtx.Err() != nil && ctx.Err() == nil {
+			return nil
+		}
+
+		if err == nil && !received {
+			// Back off rather than spin if the server keeps closing the stream straight away.
+			return errStreamClosed
+		}
+
+		return err
+	})
+}
+
+// readEvents parses a Server-Sent Events stream, calling dispatch for every complete event. It returns nil when
+// the server closes the stream.
+func readEvents(body io.Reader, dispatch func(name string, id string, data string) error) error {
+	reader := bufio.NewReader(body)
+
+	var name, id string
+
+	var data []string
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return err
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+
+		if line == "" {
+			if len(data) > 0 || name != "" {
+				if err := dispatch(name, id, strings.Join(data, "\n")); err != nil {
+					return err
+				}
+			}
+
+			name, data = "", nil
+			continue
+		}
+
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "event":
+			name = value
+		case "id":
+			id = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+}
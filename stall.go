@@ -0,0 +1,82 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ErrWatchStalled is reported to a watch's OnError hook when the server holds a newer version than the watch has
+// delivered for longer than the stall interval. The watch reconnects when this happens.
+var ErrWatchStalled = errors.New("watch stalled")
+
+// interrupter lets a stall detector abort the attempt a watch currently has in flight.
+type interrupter struct {
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+// attempt derives the context for a single attempt from ctx.
+func (i *interrupter) attempt(ctx context.Context) (context.Context, context.CancelFunc) {
+	if i == nil {
+		return ctx, func() {}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	i.mu.Lock()
+	i.cancel = cancel
+	i.mu.Unlock()
+
+	return ctx, cancel
+}
+
+func (i *interrupter) interrupt() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.cancel != nil {
+		i.cancel()
+	}
+}
+
+// detectStalls compares the version the server reports for key with the last version the tracker has seen every
+// interval, until ctx ends. A mismatch that persists across two checks counts as a stall.
+func (c *Client) detectStalls(ctx context.Context, key string, tracker *keyTracker, config *watchConfig) {
+	ticker := time.NewTicker(config.stallInterval)
+	defer ticker.Stop()
+
+	var pending string
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		_, version, _, err := c.Head(ctx, key)
+		if err != nil || version == "" || version == tracker.lastVersion() {
+			pending = ""
+			continue
+		}
+
+		if version != pending {
+			pending = version
+			continue
+		}
+
+		config.logger.Warn("watch stalled, reconnecting", slog.String("server_version", version), slog.String("watch_version", tracker.lastVersion()))
+
+		config.stats.failed(ErrWatchStalled)
+
+		if config.onError != nil {
+			config.onError(ErrWatchStalled)
+		}
+
+		config.interrupter.interrupt()
+		pending = ""
+	}
+}
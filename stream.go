@@ -0,0 +1,294 @@
+package raccoon_kv_client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// GetReader returns a reader streaming the value of key, along with its version, so that large values need not
+// be held in memory. The caller must close the reader. The client's read timeout bounds the wait for the server
+// to respond, after which the transfer is only bounded by ctx. Encrypted values cannot be decrypted as a stream
+// and are read into memory first. With ResumeDownloads an interrupted transfer picks up where it stopped. It
+// returns ErrNotFound if the key does not exist.
+func (c *Client) GetReader(ctx context.Context, key string, opts ...CallOption) (io.ReadCloser, string, error) {
+	ctx = ContextWithCallOptions(ctx, opts...)
+
+	keyUrl, err := c.keyUrl(key, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	resumes := callOptions(ctx).resumes
+
+	header := http.Header{}
+	if resumes > 0 {
+		// Offsets into a compressed transfer cannot be resumed from.
+		header.Set("accept-encoding", "identity")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	response, cancelResponse, err := c.openValue(ctx, keyUrl, header)
+	if err != nil {
+		cancel()
+		return nil, "", err
+	}
+
+	version := response.Header.Get("etag")
+
+	switch response.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		drainAndClose(response.Body)
+		cancel()
+
+		return nil, version, ErrNotFound
+	default:
+		err := newResponseError(response)
+		drainAndClose(response.Body)
+		cancel()
+
+		return nil, "", err
+	}
+
+	if resumes > 0 && version != "" {
+		response.Body = &resumingBody{
+			ctx:        ctx,
+			client:     c,
+			url:        keyUrl,
+			version:    version,
+			maxResumes: resumes,
+			body:       response.Body,
+			cancel:     cancelResponse,
+		}
+	}
+
+	body, err := c.valueReader(ctx, key, response, false)
+	if err != nil {
+		drainAndClose(response.Body)
+		cancel()
+
+		return nil, "", err
+	}
+
+	return &cancelOnClose{ReadCloser: body, cancel: cancel}, version, nil
+}
+
+// openValue sends a GET request for url with the extra headers in header, giving up if the server has not
+// responded within the adaptive or else the fixed read timeout. The returned function releases the request once its
+// body has been read.
+func (c *Client) openValue(ctx context.Context, url string, header http.Header) (*http.Response, context.CancelFunc, error) {
+	timeout := c.headerTimeoutFor(ctx)
+	if timeout <= 0 {
+		timeout = c.readTimeoutFor(ctx)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	timer := time.AfterFunc(timeout, cancel)
+
+	request, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+
+	c.acceptEncoding(request)
+
+	for name, values := range header {
+		request.Header[name] = values
+	}
+
+	response, err := c.do(request)
+	if !timer.Stop() && err == nil {
+		drainAndClose(response.Body)
+		err = context.DeadlineExceeded
+	}
+
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+
+	return response, cancel, nil
+}
+
+// valueOf returns a function that reads the value of key from a response, for fetch.
+func (c *Client) valueOf(key string) func(context.Context, *http.Response) ([]byte, error) {
+	return func(ctx context.Context, response *http.Response) ([]byte, error) {
+		return c.readValue(ctx, key, response)
+	}
+}
+
+// readValue reads the value of key in the body of response, decompressing it if it is gzip-encoded unless the
+// client passes values through, verifying its checksum and decrypting it.
+func (c *Client) readValue(ctx context.Context, key string, response *http.Response) ([]byte, error) {
+	reader, err := c.valueReader(ctx, key, response, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(reader)
+}
+
+// valueReader returns a reader for the value of key in the body of response, decompressing, verifying and decrypting it
+// as configured. If buffered is set, or the value has to be decrypted, it is read into memory and subject to the
+// client's maximum value size. Closing the reader closes the body.
+func (c *Client) valueReader(ctx context.Context, key string, response *http.Response, buffered bool) (io.ReadCloser, error) {
+	limit := c.maxValueSize
+	if !buffered && c.keys == nil {
+		limit = 0
+	}
+
+	if limit > 0 && response.ContentLength > limit {
+		return nil, &SizeLimitError{Limit: limit, Size: response.ContentLength}
+	}
+
+	var reader io.Reader = response.Body
+
+	if !c.rawValues && strings.EqualFold(response.Header.Get("content-encoding"), "gzip") {
+		decompressed, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+
+		reader = decompressed
+	}
+
+	reader = limitReader(reader, limit)
+
+	if c.expectedChecksum(response) != "" {
+		reader = &checksumReader{Reader: reader, client: c, response: response, hash: sha256.New()}
+	}
+
+	if c.keys != nil {
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err = c.decrypt(ctx, key, data)
+		if err != nil {
+			return nil, err
+		}
+
+		reader = bytes.NewReader(data)
+	}
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{reader, response.Body}, nil
+}
+
+// PutReader writes the value read from r to key without holding it in memory. If size is negative the value is
+// sent with chunked transfer encoding; otherwise r must yield exactly size bytes. Encrypted values cannot be
+// encrypted as a stream and are read into memory first, and streamed values are neither compressed nor retried.
+// Checksums are only sent if r is an io.Seeker, which is read twice.
+func (c *Client) PutReader(ctx context.Context, key string, r io.Reader, size int64) (err error) {
+	if err := c.checkPutSize(size); err != nil {
+		return err
+	}
+
+	// The size limit wraps r, so the seeker for the checksum pre-pass has to be taken first.
+	seeker, seekable := r.(io.Seeker)
+	limited := limitReader(r, c.maxPutSize)
+
+	if c.keys != nil {
+		data, err := io.ReadAll(limited)
+		if err != nil {
+			return err
+		}
+
+		return c.Put(ctx, key, data)
+	}
+
+	keyUrl, err := c.keyUrl(key, nil)
+	if err != nil {
+		return err
+	}
+
+	defer c.invalidate(key)
+
+	header := http.Header{}
+
+	if seekable && c.checksums != nil {
+		header, err = c.addStreamChecksum(limitReader(r, c.maxPutSize), seeker, header)
+		if err != nil {
+			return err
+		}
+	}
+
+	var responseHeader http.Header
+
+	if c.audit != nil {
+		record := c.auditRecord(ctx, "put", key, nil)
+		record.Size = int(size)
+
+		defer func() {
+			c.finishAudit(record, responseHeader, err)
+		}()
+	}
+
+	ctx, cancel := withTimeout(ctx, c.writeTimeoutFor(ctx))
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, "PUT", keyUrl, io.NopCloser(limited))
+	if err != nil {
+		return err
+	}
+
+	request.ContentLength = max(size, -1)
+	if size == 0 {
+		request.Body = http.NoBody
+	}
+
+	for name, values := range header {
+		request.Header[name] = values
+	}
+
+	c.setIdempotencyKey(request)
+
+	response, err := c.sendWrite(request)
+	if err != nil {
+		return err
+	}
+
+	responseHeader = response.Header
+
+	switch response.StatusCode {
+	case http.StatusNoContent:
+		return nil
+	case http.StatusPreconditionFailed:
+		return ErrConflict
+	default:
+		return newResponseError(response)
+	}
+}
+
+// addStreamChecksum adds the checksum of the value read from r to header, rewinding r afterwards.
+func (c *Client) addStreamChecksum(r io.Reader, seeker io.Seeker, header http.Header) (http.Header, error) {
+	start, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, r); err != nil {
+		return nil, err
+	}
+
+	if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	header.Set(checksumHeader, hex.EncodeToString(hash.Sum(nil)))
+
+	return header, nil
+}
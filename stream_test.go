@@ -0,0 +1,65 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPutReaderChecksumWithSizeLimit(t *testing.T) {
+	value := "streamed value"
+	sum := sha256.Sum256([]byte(value))
+
+	var checksum, body string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+
+		checksum = r.Header.Get(checksumHeader)
+		body = string(data)
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	c, err := NewClient(server.URL, WithChecksums(ChecksumReject), WithMaxPutSize(1024))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.PutReader(context.Background(), "k", strings.NewReader(value), int64(len(value))); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := hex.EncodeToString(sum[:]); checksum != want {
+		t.Errorf("checksum header = %q, want %q", checksum, want)
+	}
+
+	if body != value {
+		t.Errorf("body = %q, want %q", body, value)
+	}
+}
+
+func TestPutReaderRejectsOversizedStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	c, err := NewClient(server.URL, WithChecksums(ChecksumReject), WithMaxPutSize(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.PutReader(context.Background(), "k", strings.NewReader("too large"), -1)
+	if !errors.Is(err, ErrValueTooLarge) {
+		t.Errorf("PutReader = %v, want ErrValueTooLarge", err)
+	}
+}
@@ -0,0 +1,63 @@
+package raccoon_kv_client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// TLSOptions describes the TLS setup of a client in terms of files on disk, for use with WithTLSConfig.
+type TLSOptions struct {
+	// CAFile is a PEM bundle of root certificates trusted instead of the system pool.
+	CAFile string
+	// CertFile and KeyFile hold the client certificate and key presented for mutual TLS.
+	CertFile string
+	KeyFile  string
+	// MinVersion is the lowest accepted TLS version, e.g. tls.VersionTLS13. It defaults to TLS 1.2.
+	MinVersion uint16
+	// InsecureSkipVerify disables server certificate verification. Only use it in development.
+	InsecureSkipVerify bool
+}
+
+// Config loads the referenced files and returns the resulting TLS configuration.
+func (o TLSOptions) Config() (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion:         o.MinVersion,
+		InsecureSkipVerify: o.InsecureSkipVerify,
+	}
+
+	if config.MinVersion == 0 {
+		config.MinVersion = tls.VersionTLS12
+	}
+
+	if o.CAFile != "" {
+		pem, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ca file: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", o.CAFile)
+		}
+
+		config.RootCAs = pool
+	}
+
+	if (o.CertFile == "") != (o.KeyFile == "") {
+		return nil, errors.New("client certificate and key must be given together")
+	}
+
+	if o.CertFile != "" {
+		certificate, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+
+		config.Certificates = []tls.Certificate{certificate}
+	}
+
+	return config, nil
+}
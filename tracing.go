@@ -0,0 +1,50 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// Tracer records a span for every request the client sends, including each long-poll of a watch. Package
+// raccoonotel implements it with OpenTelemetry. Its methods are called concurrently.
+type Tracer interface {
+	// Start starts a client span for request, which performs operation: "get", "head", "put", "delete", "post",
+	// "txn" or "watch". It returns a context carrying the span, which the request is sent with, and a function
+	// that ends the span with the outcome: the response, or the error if none was received.
+	Start(request *http.Request, operation string) (ctx context.Context, end func(response *http.Response, err error))
+	// Inject adds the trace context carried by ctx to header, so that the server can continue the trace.
+	Inject(ctx context.Context, header http.Header)
+}
+
+// WithTracer traces every request with tracer and propagates the trace context to the server. Without it the
+// client does not trace.
+func WithTracer(tracer Tracer) Option {
+	return func(c *Client) {
+		c.tracer = tracer
+	}
+}
+
+// trace wraps send so that every request is sent inside a client span describing the operation.
+func (c *Client) trace(send RoundTripFunc) RoundTripFunc {
+	return func(request *http.Request) (*http.Response, error) {
+		ctx, end := c.tracer.Start(request, operationName(request))
+
+		response, err := send(request.WithContext(ctx))
+		end(response, err)
+
+		return response, err
+	}
+}
+
+// operationName classifies request for traces and metrics.
+func operationName(request *http.Request) string {
+	switch {
+	case request.URL.Query().Has("watch") || request.Header.Get("accept") == "text/event-stream":
+		return "watch"
+	case strings.HasSuffix(request.URL.Path, "/txn"):
+		return "txn"
+	default:
+		return strings.ToLower(request.Method)
+	}
+}
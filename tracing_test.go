@@ -0,0 +1,74 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+type spanKey struct{}
+
+// recordingTracer records the operation of every span and marks the context it returns, so that injection can
+// check it receives the span's context.
+type recordingTracer struct {
+	operations []string
+	statuses   []int
+}
+
+func (t *recordingTracer) Start(request *http.Request, operation string) (context.Context, func(*http.Response, error)) {
+	t.operations = append(t.operations, operation)
+
+	return context.WithValue(request.Context(), spanKey{}, operation), func(response *http.Response, err error) {
+		if err == nil {
+			t.statuses = append(t.statuses, response.StatusCode)
+		}
+	}
+}
+
+func (t *recordingTracer) Inject(ctx context.Context, header http.Header) {
+	if operation, ok := ctx.Value(spanKey{}).(string); ok {
+		header.Set("x-span", operation)
+	}
+}
+
+func TestTracer(t *testing.T) {
+	var spans []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		spans = append(spans, r.Header.Get("x-span"))
+
+		if r.Method == "PUT" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		w.Header().Set("etag", "1")
+		_, _ = w.Write([]byte("value"))
+	}))
+	defer server.Close()
+
+	tracer := &recordingTracer{}
+
+	c, err := NewClient(server.URL, WithTracer(tracer))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Put(context.Background(), "k", []byte("value")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := c.Get(context.Background(), "k"); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"put", "get"}; !slices.Equal(tracer.operations, want) || !slices.Equal(spans, want) {
+		t.Errorf("spans started = %q, sent = %q, want %q", tracer.operations, spans, want)
+	}
+
+	if len(tracer.statuses) != 2 || tracer.statuses[0] != http.StatusNoContent || tracer.statuses[1] != http.StatusOK {
+		t.Errorf("spans ended with %v, want [204 200]", tracer.statuses)
+	}
+}
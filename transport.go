@@ -0,0 +1,77 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// maxIdleConnsPerHost keeps enough idle connections around for many concurrent watches and requests, which all go
+// to the same host, instead of net/http's default of two.
+const maxIdleConnsPerHost = 64
+
+// maxDrain bounds how much of an unread response body is discarded to keep its connection alive. Larger bodies
+// are cheaper to abandon along with the connection.
+const maxDrain = 64 << 10
+
+// unixSocketUrl is the Url of clients connected to a Unix domain socket. The host is only a placeholder, the
+// transport dials the socket regardless of the address.
+const unixSocketUrl = "http://unix"
+
+// httpClientOrInit returns the HTTP client all requests go through, building the client's own pooled transport on
+// first use unless one was supplied with WithHTTPClient or WithTransport.
+func (c *Client) httpClientOrInit() *http.Client {
+	c.httpOnce.Do(func() {
+		if c.httpClient != nil {
+			return
+		}
+
+		transport := c.roundTripper
+		if transport == nil {
+			transport = c.newTransport()
+		}
+
+		c.httpClient = &http.Client{Transport: transport}
+	})
+
+	return c.httpClient
+}
+
+func (c *Client) newTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+	transport.IdleConnTimeout = 90 * time.Second
+
+	if c.tlsConfig != nil {
+		transport.TLSClientConfig = c.tlsConfig.Clone()
+	}
+
+	if c.proxy != nil {
+		transport.Proxy = http.ProxyURL(c.proxy)
+	}
+
+	if c.unixSocket != "" {
+		dialer := &net.Dialer{Timeout: 30 * time.Second}
+
+		transport.Proxy = nil
+		transport.DialContext = func(ctx context.Context, _ string, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", c.unixSocket)
+		}
+	}
+
+	return transport
+}
+
+// drainAndClose reads what is left of body, up to maxDrain bytes, before closing it, so that the connection can
+// be reused for the next request instead of being torn down.
+func drainAndClose(body io.ReadCloser) {
+	_, _ = io.CopyN(io.Discard, body, maxDrain)
+	_ = body.Close()
+}
+
+// CloseIdleConnections closes connections kept open by the client's transport that are not in use.
+func (c *Client) CloseIdleConnections() {
+	c.httpClientOrInit().CloseIdleConnections()
+}
@@ -0,0 +1,144 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// Txn groups version conditions and write operations over multiple keys that the server applies atomically.
+// If every condition holds the Then operations are applied, otherwise the Else operations are.
+type Txn struct {
+	client     *Client
+	conditions []txnCondition
+	then       []TxnOp
+	otherwise  []TxnOp
+}
+
+type txnCondition struct {
+	Key     string `json:"key"`
+	Version string `json:"version"`
+	Absent  bool   `json:"absent,omitempty"`
+}
+
+// TxnOp is a single write applied as part of a transaction branch.
+type TxnOp struct {
+	Op    string `json:"op"`
+	Key   string `json:"key"`
+	Value []byte `json:"value,omitempty"`
+}
+
+type txnRequest struct {
+	Compare []txnCondition `json:"compare"`
+	Success []TxnOp        `json:"success"`
+	Failure []TxnOp        `json:"failure"`
+}
+
+type txnResponse struct {
+	Succeeded bool `json:"succeeded"`
+}
+
+// OpPut writes data to key when its branch executes.
+func OpPut(key string, data []byte) TxnOp {
+	return TxnOp{Op: "put", Key: key, Value: data}
+}
+
+// OpDelete removes key when its branch executes.
+func OpDelete(key string) TxnOp {
+	return TxnOp{Op: "delete", Key: key}
+}
+
+// Txn starts a new transaction.
+func (c *Client) Txn() *Txn {
+	return &Txn{client: c}
+}
+
+// IfVersion requires key to be at version for the Then branch to execute.
+func (t *Txn) IfVersion(key string, version string) *Txn {
+	t.conditions = append(t.conditions, txnCondition{Key: key, Version: version})
+	return t
+}
+
+// IfAbsent requires key not to exist for the Then branch to execute.
+func (t *Txn) IfAbsent(key string) *Txn {
+	t.conditions = append(t.conditions, txnCondition{Key: key, Absent: true})
+	return t
+}
+
+// Then adds operations applied when all conditions hold.
+func (t *Txn) Then(ops ...TxnOp) *Txn {
+	t.then = append(t.then, ops...)
+	return t
+}
+
+// Else adds operations applied when any condition fails.
+func (t *Txn) Else(ops ...TxnOp) *Txn {
+	t.otherwise = append(t.otherwise, ops...)
+	return t
+}
+
+// Commit submits the transaction and reports whether the Then branch was executed.
+func (t *Txn) Commit(ctx context.Context) (succeeded bool, err error) {
+	conditions := slices.Clone(t.conditions)
+
+	for i := range conditions {
+		conditions[i].Key = t.client.serverKey(conditions[i].Key)
+
+		if err := ValidateKey(conditions[i].Key); err != nil {
+			return false, err
+		}
+	}
+
+	var keys []string
+
+	for _, op := range slices.Concat(t.then, t.otherwise) {
+		if err := ValidateKey(t.client.serverKey(op.Key)); err != nil {
+			return false, err
+		}
+
+		keys = append(keys, op.Key)
+	}
+
+	defer t.client.invalidate(keys...)
+
+	if t.client.audit != nil {
+		thenRecords := t.client.auditOps(ctx, t.conditions, t.then)
+		otherwiseRecords := t.client.auditOps(ctx, t.conditions, t.otherwise)
+
+		defer func() {
+			records := thenRecords
+			if err == nil && !succeeded {
+				records = otherwiseRecords
+			}
+
+			for _, record := range records {
+				t.client.finishAudit(record, nil, err)
+			}
+		}()
+	}
+
+	then, err := t.client.encryptOps(ctx, t.then)
+	if err != nil {
+		return false, err
+	}
+
+	otherwise, err := t.client.encryptOps(ctx, t.otherwise)
+	if err != nil {
+		return false, err
+	}
+
+	then, otherwise = t.client.namespaceOps(then), t.client.namespaceOps(otherwise)
+
+	var response txnResponse
+
+	err = t.client.postJSON(ctx, fmt.Sprintf("%s/txn", t.client.Url), txnRequest{
+		Compare: conditions,
+		Success: then,
+		Failure: otherwise,
+	}, &response)
+	if err != nil {
+		return false, err
+	}
+
+	return response.Succeeded, nil
+}
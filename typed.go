@@ -0,0 +1,101 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+// TypedClient reads and writes values of type T, encoding them with a Codec.
+type TypedClient[T any] struct {
+	client *Client
+	codec  Codec
+
+	// prefix and defaults scope a TypedClient made by TypedBucket to its bucket.
+	prefix   string
+	defaults []CallOption
+}
+
+// Typed wraps c so that values are encoded and decoded as T with codec, JSONCodec if it is nil.
+func Typed[T any](c *Client, codec Codec) *TypedClient[T] {
+	if codec == nil {
+		codec = JSONCodec
+	}
+
+	return &TypedClient[T]{client: c, codec: codec}
+}
+
+// Get returns the decoded value of key and its version. It returns ErrNotFound if the key does not exist, an error
+// wrapping ErrContentType if the value was stored with another codec's content type and one wrapping ErrDecode if
+// it cannot be decoded.
+func (t *TypedClient[T]) Get(ctx context.Context, key string) (value T, version string, err error) {
+	entry, err := t.client.GetEntry(ContextWithCallOptions(ctx, t.defaults...), t.prefix+key)
+	if err != nil {
+		return value, "", err
+	}
+
+	if err := checkContentType(t.codec, entry.ContentType); err != nil {
+		return value, "", err
+	}
+
+	value, err = t.decode(key, entry.Value)
+	if err != nil {
+		return value, "", err
+	}
+
+	return value, entry.Version, nil
+}
+
+// Put encodes value and writes it to key with the codec's content type.
+func (t *TypedClient[T]) Put(ctx context.Context, key string, value T) error {
+	data, err := t.codec.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	header := http.Header{}
+	header.Set("content-type", t.codec.ContentType())
+
+	_, err = t.client.put(ContextWithCallOptions(ctx, t.defaults...), t.prefix+key, nil, data, header)
+	return err
+}
+
+// Watch watches key and calls cb with its decoded value whenever it changes, or with the zero value once it is
+// deleted. Values that cannot be decoded are skipped and reported to the OnError hook.
+func (t *TypedClient[T]) Watch(ctx context.Context, key string, cb func(T), opts ...WatchOption) {
+	config := t.client.newWatchConfig(opts)
+
+	ctx = ContextWithCallOptions(ctx, t.defaults...)
+
+	t.client.watchEvents(ctx, t.prefix+key, t.client.watchUrl(t.prefix+key), config, func(event Event) {
+		var value T
+
+		if event.Type != EventDelete {
+			var err error
+
+			value, err = t.decode(key, event.Value)
+			if err != nil {
+				config.logger.Warn("failed to decode value, skipping it", slog.String("err", err.Error()))
+
+				if config.onError != nil {
+					config.onError(err)
+				}
+
+				return
+			}
+		}
+
+		cb(value)
+	})
+}
+
+func (t *TypedClient[T]) decode(key string, data []byte) (T, error) {
+	var value T
+
+	if err := t.codec.Unmarshal(data, &value); err != nil {
+		return value, fmt.Errorf("%w: %s: %w", ErrDecode, key, err)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,37 @@
+package raccoon_kv_client
+
+import "runtime/debug"
+
+const modulePath = "github.com/RaccoonCorp/raccoon-kv-client"
+
+// Version is the version of this module as recorded in the binary's build info, or "devel" if it is unknown, e.g.
+// when building inside the module itself.
+var Version = moduleVersion()
+
+func moduleVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "devel"
+	}
+
+	for _, dep := range info.Deps {
+		if dep.Path == modulePath {
+			if dep.Replace != nil && dep.Replace.Version != "" {
+				return dep.Replace.Version
+			}
+
+			return dep.Version
+		}
+	}
+
+	return "devel"
+}
+
+func (c *Client) userAgent() string {
+	userAgent := "raccoon-kv-client/" + Version
+	if c.userAgentSuffix != "" {
+		userAgent += " " + c.userAgentSuffix
+	}
+
+	return userAgent
+}
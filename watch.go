@@ -0,0 +1,470 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"log/slog"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ErrTooManyFailures is returned when a watch gives up after the number of consecutive failures allowed by
+// WithMaxFailures.
+var ErrTooManyFailures = errors.New("too many consecutive failures")
+
+// EventType classifies a change delivered to a watcher.
+type EventType int
+
+const (
+	// EventCreate reports a key that did not exist before.
+	EventCreate EventType = iota + 1
+	// EventUpdate reports a new value for an existing key.
+	EventUpdate
+	// EventDelete reports a key that no longer exists. It is also delivered first if the key is missing when
+	// the watch starts.
+	EventDelete
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventCreate:
+		return "create"
+	case EventUpdate:
+		return "update"
+	case EventDelete:
+		return "delete"
+	default:
+		return fmt.Sprintf("EventType(%d)", int(t))
+	}
+}
+
+// Event describes a change to a watched key.
+type Event struct {
+	Type    EventType
+	Key     string
+	Value   []byte
+	Version string
+}
+
+func (c *Client) Watch(ctx context.Context, key string, cb func([]byte), opts ...WatchOption) {
+	c.watchEvents(ctx, key, c.watchUrl(key), c.newWatchConfig(opts), func(event Event) {
+		cb(event.Value)
+	})
+}
+
+// WatchEvents watches key and calls cb with a typed Event for every change, starting with the current state.
+func (c *Client) WatchEvents(ctx context.Context, key string, cb func(Event), opts ...WatchOption) {
+	c.watchEvents(ctx, key, c.watchUrl(key), c.newWatchConfig(opts), cb)
+}
+
+// WatchChan watches key and sends an Event on the returned channel for every change, starting with the current
+// state. The channel is closed once ctx ends.
+func (c *Client) WatchChan(ctx context.Context, key string, opts ...WatchOption) (<-chan Event, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, err
+	}
+
+	requestUrl := c.watchUrl(key)
+
+	events := make(chan Event)
+
+	go func() {
+		defer close(events)
+
+		c.watchEvents(ctx, key, requestUrl, c.newWatchConfig(opts), func(event Event) {
+			select {
+			case events <- event:
+			case <-ctx.Done():
+			}
+		})
+	}()
+
+	return events, nil
+}
+
+// WatchSeq returns a sequence of events for key, for use with range. The sequence ends when the loop breaks or
+// when the watch stops, in which case the reason is yielded as the final error.
+func (c *Client) WatchSeq(ctx context.Context, key string, opts ...WatchOption) iter.Seq2[Event, error] {
+	return func(yield func(Event, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		events := make(chan Event)
+
+		var err error
+
+		go func() {
+			defer close(events)
+
+			err = c.watchEvents(ctx, key, c.watchUrl(key), c.newWatchConfig(opts), func(event Event) {
+				select {
+				case events <- event:
+				case <-ctx.Done():
+				}
+			})
+		}()
+
+		for event := range events {
+			if !yield(event, nil) {
+				cancel()
+
+				for range events {
+				}
+
+				return
+			}
+		}
+
+		yield(Event{}, err)
+	}
+}
+
+func (c *Client) watchEvents(ctx context.Context, key string, requestUrl string, config *watchConfig, emit func(Event)) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+
+	config.logger = config.logger.With(slog.String("key", key))
+
+	defer c.watchStarted(1)()
+
+	emit = config.countDelivered(emit)
+
+	if config.debounce > 0 {
+		debounced, stop := debounce(ctx, config.debounce, emit)
+		defer stop()
+
+		emit = debounced
+	}
+
+	tracker := newKeyTracker(key, config, emit)
+
+	if config.stallInterval > 0 {
+		config.interrupter = &interrupter{}
+
+		ctx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+
+		defer func() {
+			cancel()
+			<-done
+		}()
+
+		go func() {
+			defer close(done)
+
+			c.detectStalls(ctx, key, tracker, config)
+		}()
+	}
+
+	if config.sse {
+		err := c.stream(ctx, key, fmt.Sprintf("%s/kv/%s", c.Url, escapeKey(c.serverKey(key))), config, tracker.observe)
+		if !errors.Is(err, errStreamUnsupported) {
+			return err
+		}
+
+		config.logger.Info("server does not support event streams, falling back to long-polling")
+	}
+
+	return c.poll(ctx, key, requestUrl, config, tracker.observe)
+}
+
+// keyTracker turns the successive values observed for a key into typed events.
+type keyTracker struct {
+	key     string
+	config  *watchConfig
+	emit    func(Event)
+	started bool
+	exists  bool
+
+	mu      sync.Mutex
+	version string
+}
+
+func newKeyTracker(key string, config *watchConfig, emit func(Event)) *keyTracker {
+	// A resumed watch assumes the key existed at the start version, since that version came from a prior event.
+	resumed := config.startVersion != "" && config.initial != initialForce
+
+	return &keyTracker{
+		key:     key,
+		config:  config,
+		emit:    emit,
+		started: resumed,
+		exists:  resumed,
+		version: config.startVersion,
+	}
+}
+
+// lastVersion returns the most recent version observed, whether or not it was delivered.
+func (t *keyTracker) lastVersion() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.version
+}
+
+// observe records a new version of the key, where nil data means the key does not exist.
+func (t *keyTracker) observe(data []byte, version string) error {
+	t.mu.Lock()
+	t.version = version
+	t.mu.Unlock()
+
+	initial := !t.started
+	event := Event{Key: t.key, Value: data, Version: version}
+
+	switch {
+	case data == nil && (t.exists || !t.started):
+		event.Type = EventDelete
+	case data == nil:
+		return nil
+	case t.exists:
+		event.Type = EventUpdate
+	default:
+		event.Type = EventCreate
+	}
+
+	t.started = true
+	t.exists = data != nil
+
+	if initial && t.config.initial == initialSkip {
+		return nil
+	}
+
+	t.emit(event)
+
+	return nil
+}
+
+// WatchKeys watches several keys at once and funnels their changes into cb, which is never called concurrently.
+// It blocks until ctx ends.
+func (c *Client) WatchKeys(ctx context.Context, keys []string, cb func(key string, data []byte), opts ...WatchOption) {
+	var mu sync.Mutex
+
+	emit := func(event Event) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		cb(event.Key, event.Value)
+	}
+
+	if config := c.newWatchConfig(opts); config.websocket {
+		emit = config.countDelivered(emit)
+		trackers := map[string]*keyTracker{}
+
+		for _, key := range keys {
+			keyEmit := emit
+
+			if config.debounce > 0 {
+				debounced, stop := debounce(ctx, config.debounce, emit)
+				defer stop()
+
+				keyEmit = debounced
+			}
+
+			trackers[key] = newKeyTracker(key, config, keyEmit)
+		}
+
+		defer c.watchStarted(len(keys))()
+
+		c.watchWebSocket(ctx, trackers, config)
+		return
+	}
+
+	var wg sync.WaitGroup
+
+	for _, key := range keys {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			c.watchEvents(ctx, key, c.watchUrl(key), c.newWatchConfig(opts), emit)
+		}()
+	}
+
+	wg.Wait()
+}
+
+// WatchPrefix watches every key starting with prefix and calls cb with the key and its new value whenever one
+// changes. The current value of every key is delivered first, unless WithoutInitialValue is given; a key that is
+// removed is delivered with nil data. On every change the keys under prefix are listed and only those whose version
+// changed are downloaded. opts configure retries, error reporting and stall detection as for Watch; the start
+// version does not apply to a prefix. It blocks until the watch stops and returns the reason, e.g. ctx's error.
+func (c *Client) WatchPrefix(ctx context.Context, prefix string, cb func(key string, data []byte), opts ...WatchOption) error {
+	if err := validatePrefix(prefix); err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	query.Set("prefix", c.serverKey(prefix))
+	query.Set("watch", fmt.Sprintf("%d", c.watchSeconds()))
+
+	requestUrl := fmt.Sprintf("%s/kv?%s", c.Url, query.Encode())
+
+	config := c.newWatchConfig(opts)
+	config.logger = config.logger.With(slog.String("prefix", prefix))
+	config.startVersion = ""
+	// The list page is not a value and is listed again below, so only its version matters.
+	config.versionOnly = true
+
+	versions := map[string]string{}
+	deliver := config.initial != initialSkip
+
+	defer c.watchStarted(1)()
+
+	return c.poll(ctx, "", requestUrl, config, func(_ []byte, _ string) error {
+		keys, err := c.List(ctx, prefix)
+		if err != nil {
+			return err
+		}
+
+		current := make(map[string]string, len(keys))
+
+		var changed []string
+
+		for _, key := range keys {
+			current[key.Key] = key.Version
+
+			if version, ok := versions[key.Key]; !ok || version != key.Version {
+				changed = append(changed, key.Key)
+			}
+		}
+
+		values := c.BatchGet(ctx, changed)
+
+		for _, key := range changed {
+			result := values[key]
+
+			switch {
+			case errors.Is(result.Err, ErrNotFound):
+				delete(current, key)
+			case result.Err != nil:
+				return result.Err
+			default:
+				current[key] = result.Version
+			}
+		}
+
+		if deliver {
+			for _, key := range changed {
+				if result := values[key]; result.Err == nil {
+					cb(key, result.Data)
+				}
+			}
+
+			for key := range versions {
+				if _, ok := current[key]; !ok {
+					cb(key, nil)
+				}
+			}
+		}
+
+		versions = current
+		deliver = true
+
+		return nil
+	})
+}
+
+func (c *Client) watchUrl(key string) string {
+	return fmt.Sprintf("%s/kv/%s?watch=%d", c.Url, escapeKey(c.serverKey(key)), c.watchSeconds())
+}
+
+// poll long-polls requestUrl, the value of key, until ctx ends, calling onChange whenever the returned version
+// differs from the last one seen. If onChange fails the change is retried after backing off. It returns the reason
+// the watch stopped.
+func (c *Client) poll(ctx context.Context, key string, requestUrl string, config *watchConfig, onChange func(data []byte, version string) error) error {
+	ctx = withoutRetries(ctx)
+
+	lastVersion := config.startVersion
+	if config.initial == initialForce {
+		lastVersion = ""
+	}
+
+	return retry(ctx, config, func() error {
+		attemptCtx, cancel := config.interrupter.attempt(ctx)
+		defer cancel()
+
+		pollUrl, pollTimeout := c.pollDuration(ctx, requestUrl)
+
+		read := c.valueOf(key)
+		if config.versionOnly {
+			read = skipBody
+		}
+
+		data, version, err := c.fetch(attemptCtx, pollUrl, lastVersion, pollTimeout, read)
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+				config.logger.Debug("internal http client timeout, retrying")
+				return nil
+			}
+
+			if attemptCtx.Err() != nil && ctx.Err() == nil {
+				return nil
+			}
+
+			return err
+		}
+
+		config.stats.succeeded()
+
+		if lastVersion != version {
+			if err := onChange(data, version); err != nil {
+				return err
+			}
+
+			lastVersion = version
+		}
+
+		return nil
+	})
+}
+
+// retry calls attempt until ctx ends, backing off after each failed attempt and giving up once the watch's
+// failure limit is reached.
+func retry(ctx context.Context, config *watchConfig, attempt func() error) error {
+	failures := 0
+
+	for {
+		err := attempt()
+		if err == nil {
+			failures = 0
+			continue
+		}
+
+		if errors.Is(err, errStreamUnsupported) {
+			return err
+		}
+
+		if ctx.Err() != nil {
+			config.logger.Debug("context cancelled or deadline exceeded, stopping watch")
+			return ctx.Err()
+		}
+
+		failures++
+		delay := config.backoff.Delay(failures)
+
+		config.stats.failed(err)
+
+		config.logger.Warn("failed to query kv store, backing off", slog.String("err", err.Error()), requestIDAttr(err), slog.Duration("backoff", delay))
+
+		if config.onError != nil {
+			config.onError(err)
+		}
+
+		if config.maxFailures > 0 && failures >= config.maxFailures {
+			config.logger.Error("too many consecutive failures, stopping watch", slog.Int("failures", failures))
+			return fmt.Errorf("%w: %w", ErrTooManyFailures, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			config.logger.Debug("context cancelled or deadline exceeded, stopping watch")
+			return ctx.Err()
+		case <-time.NewTimer(delay).C:
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package raccoon_kv_client
+
+import "context"
+
+// WatchHandle controls a watch running in the background.
+type WatchHandle struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+	stats  *watchStats
+}
+
+// StartWatch watches key in the background, calling cb with a typed Event for every change, and returns a handle
+// for stopping the watch and inspecting why it ended.
+func (c *Client) StartWatch(ctx context.Context, key string, cb func(Event), opts ...WatchOption) *WatchHandle {
+	ctx, cancel := context.WithCancel(ctx)
+
+	config := c.newWatchConfig(opts)
+
+	handle := &WatchHandle{
+		cancel: cancel,
+		done:   make(chan struct{}),
+		stats:  config.stats,
+	}
+
+	go func() {
+		defer close(handle.done)
+		defer cancel()
+
+		handle.err = c.watchEvents(ctx, key, c.watchUrl(key), config, cb)
+	}()
+
+	return handle
+}
+
+// Stop ends the watch and waits for it to terminate.
+func (h *WatchHandle) Stop() {
+	h.cancel()
+	<-h.done
+}
+
+// Done is closed once the watch has terminated.
+func (h *WatchHandle) Done() <-chan struct{} {
+	return h.done
+}
+
+// Err returns why the watch terminated, or nil while it is still running. A watch ended by Stop or by its context
+// reports the context error; one that gave up reports an error wrapping ErrTooManyFailures.
+func (h *WatchHandle) Err() error {
+	select {
+	case <-h.done:
+		return h.err
+	default:
+		return nil
+	}
+}
+
+// Stats returns a snapshot of the watch's health counters.
+func (h *WatchHandle) Stats() WatchStats {
+	return h.stats.snapshot()
+}
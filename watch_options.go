@@ -0,0 +1,148 @@
+package raccoon_kv_client
+
+import (
+	"log/slog"
+	"time"
+)
+
+type initialDelivery int
+
+const (
+	initialDefault initialDelivery = iota
+	initialSkip
+	initialForce
+)
+
+type watchConfig struct {
+	startVersion string
+	initial      initialDelivery
+	onError      func(error)
+	maxFailures  int
+	backoff      Backoff
+	debounce     time.Duration
+	sse          bool
+	websocket    bool
+	// versionOnly makes polls ignore the response body, for watches that only need to learn the version changed.
+	versionOnly bool
+
+	stallInterval time.Duration
+	interrupter   *interrupter
+	stats         *watchStats
+	logger        *slog.Logger
+	onEvent       func(Event)
+}
+
+// WatchOption configures a single watch.
+type WatchOption func(*watchConfig)
+
+// WithStartVersion resumes a watch from a version the caller has already processed, e.g. one persisted across a
+// restart. Nothing is delivered until the key moves past that version.
+func WithStartVersion(version string) WatchOption {
+	return func(config *watchConfig) {
+		config.startVersion = version
+	}
+}
+
+// WithoutInitialValue only delivers changes made after the watch starts; the current value is read but not
+// delivered.
+func WithoutInitialValue() WatchOption {
+	return func(config *watchConfig) {
+		config.initial = initialSkip
+	}
+}
+
+// WithInitialValue always delivers the current value first, even when resuming with WithStartVersion at the
+// current version.
+func WithInitialValue() WatchOption {
+	return func(config *watchConfig) {
+		config.initial = initialForce
+	}
+}
+
+// OnError registers a hook called with every error the watch encounters before it backs off and retries, so
+// applications can surface a degraded watch in their own health checks.
+func OnError(fn func(error)) WatchOption {
+	return func(config *watchConfig) {
+		config.onError = fn
+	}
+}
+
+// WithMaxFailures stops the watch once n consecutive attempts have failed. Zero, the default, retries forever.
+func WithMaxFailures(n int) WatchOption {
+	return func(config *watchConfig) {
+		config.maxFailures = n
+	}
+}
+
+// WithBackoff overrides the client's Backoff for this watch.
+func WithBackoff(backoff Backoff) WatchOption {
+	return func(config *watchConfig) {
+		config.backoff = backoff
+	}
+}
+
+// WithDebounce coalesces bursts of changes: an event is only delivered once the key has been quiet for d, and
+// only the most recent value of the burst is delivered.
+func WithDebounce(d time.Duration) WatchOption {
+	return func(config *watchConfig) {
+		config.debounce = d
+	}
+}
+
+// WithStallDetection checks every interval whether the server holds a newer version than the watch has
+// delivered. If it still does at the following check, the watch is considered stalled: ErrWatchStalled is reported
+// to the OnError hook and the watch reconnects. Only single-key watches support stall detection.
+func WithStallDetection(interval time.Duration) WatchOption {
+	return func(config *watchConfig) {
+		config.stallInterval = interval
+	}
+}
+
+// WithSSE streams changes over a single Server-Sent Events connection instead of repeated long-polls. If the
+// server does not answer with an event stream, the watch falls back to long-polling.
+func WithSSE() WatchOption {
+	return func(config *watchConfig) {
+		config.sse = true
+	}
+}
+
+// WithWebSocket makes WatchKeys multiplex all of its keys over a single WebSocket connection instead of running
+// one long-poll per key. Other watch functions ignore it.
+func WithWebSocket() WatchOption {
+	return func(config *watchConfig) {
+		config.websocket = true
+	}
+}
+
+func (c *Client) newWatchConfig(opts []WatchOption) *watchConfig {
+	config := &watchConfig{
+		backoff: c.Backoff,
+		stats:   &watchStats{},
+		logger:  c.log(),
+		onEvent: c.hooks.OnWatchEvent,
+	}
+
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	if config.backoff == nil {
+		config.backoff = DefaultBackoff
+	}
+
+	return config
+}
+
+// countDelivered wraps emit so that every delivered event is counted in the watch's stats and passed to the
+// client's OnWatchEvent hook.
+func (config *watchConfig) countDelivered(emit func(Event)) func(Event) {
+	return func(event Event) {
+		config.stats.delivered()
+
+		if config.onEvent != nil {
+			config.onEvent(event)
+		}
+
+		emit(event)
+	}
+}
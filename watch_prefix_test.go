@@ -0,0 +1,284 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type staticKeys map[string][]byte
+
+func (k staticKeys) CurrentKey(context.Context) (string, []byte, error) {
+	return "k1", k["k1"], nil
+}
+
+func (k staticKeys) Key(_ context.Context, id string) ([]byte, error) {
+	return k[id], nil
+}
+
+// prefixServer stores values as sent, serves them and answers prefix listings, long-polling them until the store
+// changes. It counts the values it serves.
+type prefixServer struct {
+	mu       sync.Mutex
+	values   map[string][]byte
+	versions map[string]int
+	version  int
+	changed  chan struct{}
+	gets     map[string]int
+}
+
+func newPrefixServer() *prefixServer {
+	return &prefixServer{values: map[string][]byte{}, versions: map[string]int{}, changed: make(chan struct{}), gets: map[string]int{}}
+}
+
+func (s *prefixServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "PUT" || r.Method == "DELETE" {
+		data, _ := io.ReadAll(r.Body)
+
+		s.mu.Lock()
+		key := strings.TrimPrefix(r.URL.Path, "/kv/")
+		if r.Method == "PUT" {
+			s.values[key] = data
+		} else {
+			delete(s.values, key)
+		}
+		s.version++
+		s.versions[key] = s.version
+		close(s.changed)
+		s.changed = make(chan struct{})
+		s.mu.Unlock()
+
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if key, ok := strings.CutPrefix(r.URL.Path, "/kv/"); ok {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.gets[key]++
+
+		value, ok := s.values[key]
+		if !ok {
+			w.Header().Set("etag", fmt.Sprint(s.version))
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("etag", fmt.Sprint(s.versions[key]))
+		_, _ = w.Write(value)
+		return
+	}
+
+	s.mu.Lock()
+	etag := fmt.Sprint(s.version)
+	changed := s.changed
+	s.mu.Unlock()
+
+	if r.Header.Get("if-none-match") == etag {
+		select {
+		case <-changed:
+		case <-time.After(100 * time.Millisecond):
+		case <-r.Context().Done():
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	etag = fmt.Sprint(s.version)
+	w.Header().Set("etag", etag)
+
+	if r.Header.Get("if-none-match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	page := scanPage{}
+	for key, value := range s.values {
+		if strings.HasPrefix(key, r.URL.Query().Get("prefix")) {
+			entry := KeyValue{Key: key, Version: fmt.Sprint(s.versions[key])}
+			if r.URL.Query().Get("values") == "true" {
+				entry.Value = value
+			}
+
+			page.Keys = append(page.Keys, entry)
+		}
+	}
+
+	_ = json.NewEncoder(w).Encode(page)
+}
+
+func TestWatchPrefixWithEncryption(t *testing.T) {
+	server := httptest.NewServer(newPrefixServer())
+	defer server.Close()
+
+	c, err := NewClient(server.URL, WithEncryption(staticKeys{"k1": make([]byte, 32)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	delivered := make(chan string, 1)
+
+	go c.WatchPrefix(ctx, "app/", func(key string, data []byte) {
+		select {
+		case delivered <- key + "=" + string(data):
+		default:
+		}
+	})
+
+	if err := c.Put(ctx, "app/a", []byte("secret")); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case got := <-delivered:
+		if got != "app/a=secret" {
+			t.Fatalf("delivered %q, want %q", got, "app/a=secret")
+		}
+	case <-ctx.Done():
+		t.Fatal("no change delivered")
+	}
+}
+
+// prefixChange is a change delivered by WatchPrefix.
+type prefixChange struct {
+	key     string
+	value   string
+	deleted bool
+}
+
+// watchPrefix runs WatchPrefix in the background, sending the changes it delivers and finally its result.
+func watchPrefix(ctx context.Context, c *Client, prefix string, opts ...WatchOption) (<-chan prefixChange, <-chan error) {
+	changes := make(chan prefixChange, 16)
+	result := make(chan error, 1)
+
+	go func() {
+		result <- c.WatchPrefix(ctx, prefix, func(key string, data []byte) {
+			changes <- prefixChange{key: key, value: string(data), deleted: data == nil}
+		}, opts...)
+	}()
+
+	return changes, result
+}
+
+func nextChange(t *testing.T, changes <-chan prefixChange) prefixChange {
+	t.Helper()
+
+	select {
+	case change := <-changes:
+		return change
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change delivered")
+		return prefixChange{}
+	}
+}
+
+func TestWatchPrefixDownloadsChangedKeys(t *testing.T) {
+	store := newPrefixServer()
+
+	server := httptest.NewServer(store)
+	defer server.Close()
+
+	c, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for _, key := range []string{"app/a", "app/b", "other/c"} {
+		if err := c.Put(ctx, key, []byte("v1")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	changes, result := watchPrefix(ctx, c, "app/")
+
+	initial := map[string]string{}
+	for range 2 {
+		change := nextChange(t, changes)
+		initial[change.key] = change.value
+	}
+
+	if initial["app/a"] != "v1" || initial["app/b"] != "v1" {
+		t.Fatalf("initial values = %v, want app/a and app/b", initial)
+	}
+
+	if err := c.Put(ctx, "app/b", []byte("v2")); err != nil {
+		t.Fatal(err)
+	}
+
+	if change := nextChange(t, changes); change != (prefixChange{key: "app/b", value: "v2"}) {
+		t.Errorf("change = %+v, want app/b=v2", change)
+	}
+
+	if err := c.Delete(ctx, "app/a"); err != nil {
+		t.Fatal(err)
+	}
+
+	if change := nextChange(t, changes); change != (prefixChange{key: "app/a", deleted: true}) {
+		t.Errorf("change = %+v, want app/a deleted", change)
+	}
+
+	store.mu.Lock()
+	gets := maps.Clone(store.gets)
+	store.mu.Unlock()
+
+	if gets["app/a"] != 1 || gets["app/b"] != 2 || gets["other/c"] != 0 {
+		t.Errorf("values downloaded = %v, want app/a once and app/b twice", gets)
+	}
+
+	cancel()
+
+	if err := <-result; !errors.Is(err, context.Canceled) {
+		t.Errorf("WatchPrefix = %v, want context.Canceled", err)
+	}
+}
+
+func TestWatchPrefixOptions(t *testing.T) {
+	server := httptest.NewServer(newPrefixServer())
+	defer server.Close()
+
+	c, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := c.WatchPrefix(ctx, "app//", func(string, []byte) {}); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("WatchPrefix with an empty segment = %v, want ErrInvalidKey", err)
+	}
+
+	if err := c.Put(ctx, "app/a", []byte("v1")); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, _ := watchPrefix(ctx, c, "app/", WithoutInitialValue())
+
+	// Give the watch time to list the existing key before the next change.
+	time.Sleep(50 * time.Millisecond)
+
+	if err := c.Put(ctx, "app/b", []byte("v1")); err != nil {
+		t.Fatal(err)
+	}
+
+	if change := nextChange(t, changes); change != (prefixChange{key: "app/b", value: "v1"}) {
+		t.Errorf("first change = %+v, want app/b=v1 without the initial app/a", change)
+	}
+}
@@ -0,0 +1,54 @@
+package raccoon_kv_client
+
+import (
+	"sync"
+	"time"
+)
+
+// WatchStats is a snapshot of a watch's health.
+type WatchStats struct {
+	// EventsDelivered counts the events passed to the watch's callback.
+	EventsDelivered int64
+	// Reconnects counts how often the watch had to re-establish itself after a failure or a detected stall.
+	Reconnects int64
+	// LastSuccess is when the watch last heard from the server.
+	LastSuccess time.Time
+	// LastError is the most recent failure, and LastErrorAt when it happened.
+	LastError   error
+	LastErrorAt time.Time
+}
+
+type watchStats struct {
+	mu    sync.Mutex
+	stats WatchStats
+}
+
+func (s *watchStats) delivered() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stats.EventsDelivered++
+}
+
+func (s *watchStats) succeeded() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stats.LastSuccess = time.Now()
+}
+
+func (s *watchStats) failed(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stats.Reconnects++
+	s.stats.LastError = err
+	s.stats.LastErrorAt = time.Now()
+}
+
+func (s *watchStats) snapshot() WatchStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.stats
+}
@@ -0,0 +1,325 @@
+package raccoon_kv_client
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	websocketGuid       = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	websocketMaxMessage = 64 << 20
+
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xa
+)
+
+type websocketSubscription struct {
+	Key     string `json:"key"`
+	Version string `json:"version,omitempty"`
+}
+
+type websocketSubscribe struct {
+	Watch []websocketSubscription `json:"watch"`
+}
+
+type websocketEvent struct {
+	Key     string `json:"key"`
+	Version string `json:"version"`
+	Value   []byte `json:"value"`
+	Deleted bool   `json:"deleted"`
+}
+
+// watchWebSocket multiplexes watches on all trackers' keys over a single WebSocket connection to /watch,
+// reconnecting and resubscribing from the last seen versions whenever the connection drops. The first subscription
+// starts from the watch's start version, and every reconnect waits at least the first backoff delay, even after a
+// clean close, so that a server that keeps closing the connection is not hammered.
+func (c *Client) watchWebSocket(ctx context.Context, trackers map[string]*keyTracker, config *watchConfig) error {
+	ctx = withoutRetries(ctx)
+
+	versions := map[string]string{}
+	if config.initial != initialForce {
+		for key, tracker := range trackers {
+			versions[key] = tracker.lastVersion()
+		}
+	}
+
+	return retry(ctx, config, func() error {
+		conn, err := c.dialWebSocket(ctx, fmt.Sprintf("%s/watch", c.Url))
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		subscribe := websocketSubscribe{}
+		for key := range trackers {
+			subscribe.Watch = append(subscribe.Watch, websocketSubscription{Key: c.serverKey(key), Version: versions[key]})
+		}
+
+		message, err := json.Marshal(subscribe)
+		if err != nil {
+			return err
+		}
+
+		if err := conn.WriteMessage(opText, message); err != nil {
+			return err
+		}
+
+		received := false
+
+		config.stats.succeeded()
+
+		for {
+			message, err := conn.ReadMessage()
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					if !received {
+						return errStreamClosed
+					}
+
+					select {
+					case <-ctx.Done():
+						return ctx.Err()
+					case <-time.After(config.backoff.Delay(1)):
+						return nil
+					}
+				}
+
+				return err
+			}
+
+			received = true
+
+			config.stats.succeeded()
+
+			var event websocketEvent
+			if err := json.Unmarshal(message, &event); err != nil {
+				return fmt.Errorf("malformed watch event: %w", err)
+			}
+
+			event.Key = c.clientKey(event.Key)
+
+			tracker, ok := trackers[event.Key]
+			if !ok || event.Version == versions[event.Key] {
+				continue
+			}
+
+			data := event.Value
+			if event.Deleted {
+				data = nil
+			} else if data == nil {
+				data = []byte{}
+			}
+
+			data, err = c.decrypt(ctx, event.Key, data)
+			if err != nil {
+				return err
+			}
+
+			if err := tracker.observe(data, event.Version); err != nil {
+				return err
+			}
+
+			versions[event.Key] = event.Version
+		}
+	})
+}
+
+// websocketConn is a minimal RFC 6455 client connection, sufficient for exchanging JSON messages.
+type websocketConn struct {
+	conn   io.ReadWriteCloser
+	reader *bufio.Reader
+	stop   func() bool
+
+	writeMu sync.Mutex
+}
+
+func (c *Client) dialWebSocket(ctx context.Context, requestUrl string) (*websocketConn, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	request, err := http.NewRequestWithContext(ctx, "GET", requestUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("connection", "Upgrade")
+	request.Header.Set("upgrade", "websocket")
+	request.Header.Set("sec-websocket-version", "13")
+	request.Header.Set("sec-websocket-key", key)
+
+	response, err := c.do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusSwitchingProtocols {
+		_ = response.Body.Close()
+		return nil, newResponseError(response)
+	}
+
+	accept := sha1.Sum([]byte(key + websocketGuid))
+	if response.Header.Get("sec-websocket-accept") != base64.StdEncoding.EncodeToString(accept[:]) {
+		_ = response.Body.Close()
+		return nil, errors.New("invalid websocket handshake")
+	}
+
+	conn, ok := response.Body.(io.ReadWriteCloser)
+	if !ok {
+		_ = response.Body.Close()
+		return nil, errors.New("websocket upgrade not supported by transport")
+	}
+
+	return &websocketConn{
+		conn:   conn,
+		reader: bufio.NewReader(conn),
+		stop: context.AfterFunc(ctx, func() {
+			_ = conn.Close()
+		}),
+	}, nil
+}
+
+// ReadMessage returns the next text or binary message, answering pings along the way. It returns io.EOF once
+// the server closes the connection.
+func (c *websocketConn) ReadMessage() ([]byte, error) {
+	var message []byte
+
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return nil, err
+		}
+
+		switch opcode {
+		case opPing:
+			if err := c.WriteMessage(opPong, payload); err != nil {
+				return nil, err
+			}
+		case opPong:
+		case opClose:
+			_ = c.WriteMessage(opClose, nil)
+			return nil, io.EOF
+		case opText, opBinary, opContinuation:
+			if len(message)+len(payload) > websocketMaxMessage {
+				return nil, errors.New("websocket message too large")
+			}
+
+			message = append(message, payload...)
+
+			if fin {
+				return message, nil
+			}
+		default:
+			return nil, fmt.Errorf("unexpected websocket opcode %d", opcode)
+		}
+	}
+}
+
+// WriteMessage sends payload as a single masked frame.
+func (c *websocketConn) WriteMessage(opcode byte, payload []byte) error {
+	frame := []byte{0x80 | opcode}
+
+	switch {
+	case len(payload) < 126:
+		frame = append(frame, 0x80|byte(len(payload)))
+	case len(payload) <= 0xffff:
+		frame = append(frame, 0x80|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(len(payload)))
+	default:
+		frame = append(frame, 0x80|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(len(payload)))
+	}
+
+	var mask [4]byte
+	if _, err := rand.Read(mask[:]); err != nil {
+		return err
+	}
+
+	frame = append(frame, mask[:]...)
+
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+func (c *websocketConn) Close() error {
+	c.stop()
+	return c.conn.Close()
+}
+
+func (c *websocketConn) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	var header [2]byte
+	if _, err := io.ReadFull(c.reader, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+
+	fin = header[0]&0x80 != 0
+	opcode = header[0] & 0x0f
+	masked := header[1]&0x80 != 0
+	length := uint64(header[1] & 0x7f)
+
+	switch length {
+	case 126:
+		var extended [2]byte
+		if _, err := io.ReadFull(c.reader, extended[:]); err != nil {
+			return false, 0, nil, err
+		}
+
+		length = uint64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		if _, err := io.ReadFull(c.reader, extended[:]); err != nil {
+			return false, 0, nil, err
+		}
+
+		length = binary.BigEndian.Uint64(extended[:])
+	}
+
+	if length > websocketMaxMessage {
+		return false, 0, nil, errors.New("websocket frame too large")
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.reader, mask[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return false, 0, nil, err
+	}
+
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+
+	return fin, opcode, payload, nil
+}
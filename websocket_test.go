@@ -0,0 +1,210 @@
+package raccoon_kv_client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// websocketServer accepts watch connections and records each subscription. It sends every subscribed key's history,
+// versions 1 and 2, to subscriptions without a version, and only the current version 2 otherwise, and then closes
+// the connection cleanly.
+type websocketServer struct {
+	mu            sync.Mutex
+	subscriptions []websocketSubscribe
+}
+
+func (s *websocketServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	netConn, buffered, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		return
+	}
+	defer netConn.Close()
+
+	accept := sha1.Sum([]byte(r.Header.Get("sec-websocket-key") + websocketGuid))
+	_, _ = fmt.Fprintf(buffered, "HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n"+
+		"Sec-WebSocket-Accept: %s\r\n\r\n", base64.StdEncoding.EncodeToString(accept[:]))
+	_ = buffered.Flush()
+
+	conn := &websocketConn{conn: netConn, reader: bufio.NewReader(buffered), stop: func() bool { return true }}
+
+	message, err := conn.ReadMessage()
+	if err != nil {
+		return
+	}
+
+	var subscribe websocketSubscribe
+	if err := json.Unmarshal(message, &subscribe); err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	s.subscriptions = append(s.subscriptions, subscribe)
+	s.mu.Unlock()
+
+	for _, subscription := range subscribe.Watch {
+		versions := []string{"2"}
+		if subscription.Version == "" {
+			versions = []string{"1", "2"}
+		}
+
+		for _, version := range versions {
+			event, _ := json.Marshal(websocketEvent{Key: subscription.Key, Version: version, Value: []byte("v" + version)})
+			if err := conn.WriteMessage(opText, event); err != nil {
+				return
+			}
+		}
+	}
+
+	_ = conn.WriteMessage(opClose, nil)
+}
+
+func TestWatchWebSocket(t *testing.T) {
+	ws := &websocketServer{}
+
+	server := httptest.NewServer(ws)
+	defer server.Close()
+
+	c, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 350*time.Millisecond)
+	defer cancel()
+
+	var delivered []string
+
+	c.WatchKeys(ctx, []string{"k"}, func(key string, data []byte) {
+		delivered = append(delivered, string(data))
+	}, WithWebSocket(), WithStartVersion("1"), WithBackoff(ExponentialBackoff{Initial: 100 * time.Millisecond, Max: 100 * time.Millisecond}))
+
+	if len(delivered) != 1 || delivered[0] != "v2" {
+		t.Errorf("delivered = %q, want only %q", delivered, "v2")
+	}
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if n := len(ws.subscriptions); n < 2 || n > 4 {
+		t.Fatalf("connections = %d, want between 2 and 4 with a 100ms backoff between clean closes", n)
+	}
+
+	for i, want := range []string{"1", "2"} {
+		if got := ws.subscriptions[i].Watch[0].Version; got != want {
+			t.Errorf("subscription %d resumed from version %q, want %q", i, got, want)
+		}
+	}
+}
+
+// websocketPipe connects two websocketConns over an in-memory pipe.
+func websocketPipe() (client *websocketConn, server *websocketConn) {
+	a, b := net.Pipe()
+
+	wrap := func(conn net.Conn) *websocketConn {
+		return &websocketConn{conn: conn, reader: bufio.NewReader(conn), stop: func() bool { return true }}
+	}
+
+	return wrap(a), wrap(b)
+}
+
+func TestWebSocketFraming(t *testing.T) {
+	tests := []struct {
+		name   string
+		length int
+	}{
+		{"empty", 0},
+		{"short", 125},
+		{"16-bit length", 126},
+		{"largest 16-bit length", 0xffff},
+		{"64-bit length", 0x10000},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, server := websocketPipe()
+			defer client.Close()
+			defer server.Close()
+
+			payload := make([]byte, test.length)
+			for i := range payload {
+				payload[i] = byte(i)
+			}
+
+			written := make(chan error, 1)
+			go func() { written <- server.WriteMessage(opBinary, payload) }()
+
+			message, err := client.ReadMessage()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := <-written; err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(message, payload) {
+				t.Errorf("ReadMessage returned %d bytes, want the %d written", len(message), len(payload))
+			}
+		})
+	}
+}
+
+func TestWebSocketFragmentsAndControlFrames(t *testing.T) {
+	a, b := net.Pipe()
+	defer b.Close()
+
+	client := &websocketConn{conn: a, reader: bufio.NewReader(a), stop: func() bool { return true }}
+	defer client.Close()
+
+	server := &websocketConn{conn: b, reader: bufio.NewReader(b)}
+
+	// The server sends "hello" in two unmasked fragments with a ping between them, and then closes.
+	go func() {
+		_, _ = b.Write([]byte{opText, 3, 'h', 'e', 'l'})
+		_, _ = b.Write([]byte{0x80 | opPing, 4, 'p', 'i', 'n', 'g'})
+		_, _ = b.Write([]byte{0x80 | opContinuation, 2, 'l', 'o'})
+		_, _ = b.Write([]byte{0x80 | opClose, 0})
+	}()
+
+	pong := make(chan []byte, 1)
+	go func() {
+		_, opcode, payload, err := server.readFrame()
+		if err == nil && opcode == opPong {
+			pong <- payload
+		}
+
+		close(pong)
+	}()
+
+	message, err := client.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(message) != "hello" {
+		t.Errorf("ReadMessage = %q, want %q", message, "hello")
+	}
+
+	if payload := <-pong; string(payload) != "ping" {
+		t.Errorf("pong payload = %q, want %q", payload, "ping")
+	}
+
+	go func() { _, _, _, _ = server.readFrame() }()
+
+	if _, err := client.ReadMessage(); !errors.Is(err, io.EOF) {
+		t.Errorf("ReadMessage after close = %v, want io.EOF", err)
+	}
+}
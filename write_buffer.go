@@ -0,0 +1,181 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ErrWriteBufferClosed is returned by WriteBuffer.Put after the buffer has been closed.
+var ErrWriteBufferClosed = errors.New("write buffer closed")
+
+// WriteBufferOptions configures a WriteBuffer. Zero fields take their defaults.
+type WriteBufferOptions struct {
+	// FlushInterval is how often buffered writes are sent. It defaults to one second.
+	FlushInterval time.Duration
+	// MaxPending is the number of buffered keys that triggers a flush before the interval has elapsed. It
+	// defaults to 1000.
+	MaxPending int
+	// OnError is called with the error of every flush made in the background.
+	OnError func(error)
+}
+
+// WriteBuffer coalesces writes for keys where only the latest value matters, such as telemetry: a Put replaces
+// any value still buffered for its key, and buffered values are written in a batch every flush interval or once
+// enough keys are pending. Writes that fail with a transient error, because the server could not be reached or
+// was overloaded, are buffered again unless a newer value has been put meanwhile; other failures, such as a
+// rejected key or value, are dropped and reported.
+type WriteBuffer struct {
+	client *Client
+	opts   WriteBufferOptions
+
+	mu      sync.Mutex
+	pending map[string][]byte
+	closed  bool
+
+	flushMu sync.Mutex
+	trigger chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// NewWriteBuffer returns a write buffer that writes through c. It must be closed to write the final values and
+// stop flushing.
+func (c *Client) NewWriteBuffer(opts WriteBufferOptions) *WriteBuffer {
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = time.Second
+	}
+
+	if opts.MaxPending <= 0 {
+		opts.MaxPending = 1000
+	}
+
+	b := &WriteBuffer{
+		client:  c,
+		opts:    opts,
+		pending: map[string][]byte{},
+		trigger: make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	go b.run()
+
+	return b
+}
+
+// Put buffers data as the next value of key, replacing any value still buffered for it.
+func (b *WriteBuffer) Put(key string, data []byte) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrWriteBufferClosed
+	}
+
+	b.pending[key] = slices.Clone(data)
+
+	if len(b.pending) >= b.opts.MaxPending {
+		select {
+		case b.trigger <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// Flush writes all buffered values now. If any write fails the error is a *BatchError, and only the writes that
+// failed with a transient error stay buffered.
+func (b *WriteBuffer) Flush(ctx context.Context) error {
+	b.flushMu.Lock()
+	defer b.flushMu.Unlock()
+
+	b.mu.Lock()
+	batch := b.pending
+	b.pending = map[string][]byte{}
+	b.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	err := b.client.BatchPut(ctx, batch)
+
+	var batchErr *BatchError
+	if errors.As(err, &batchErr) {
+		b.mu.Lock()
+		for key, err := range batchErr.Errors {
+			if _, newer := b.pending[key]; !newer && transient(err) {
+				b.pending[key] = batch[key]
+			}
+		}
+		b.mu.Unlock()
+	}
+
+	return err
+}
+
+// Close stops the background flushing and writes the values still buffered. Values that cannot be written are
+// dropped and reported in the returned error.
+func (b *WriteBuffer) Close(ctx context.Context) error {
+	b.mu.Lock()
+	closed := b.closed
+	b.closed = true
+	b.mu.Unlock()
+
+	if closed {
+		return nil
+	}
+
+	close(b.stop)
+	<-b.done
+
+	return b.Flush(ctx)
+}
+
+func (b *WriteBuffer) run() {
+	defer close(b.done)
+
+	ticker := time.NewTicker(b.opts.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-ticker.C:
+		case <-b.trigger:
+		}
+
+		if err := b.Flush(context.Background()); err != nil && b.opts.OnError != nil {
+			b.opts.OnError(err)
+		}
+	}
+}
+
+// transient reports whether a write that failed with err may succeed if it is sent again: the flush was cut short,
+// the server could not be reached or answered with a server error, a timeout or 429 Too Many Requests, or the
+// client's circuit breaker or rate limiter held the write back.
+func transient(err error) bool {
+	var responseErr *ResponseError
+	if errors.As(err, &responseErr) {
+		return responseErr.StatusCode >= http.StatusInternalServerError ||
+			responseErr.StatusCode == http.StatusTooManyRequests || responseErr.StatusCode == http.StatusRequestTimeout
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, ErrCircuitOpen) || errors.Is(err, ErrRateLimited)
+}
@@ -0,0 +1,79 @@
+package raccoon_kv_client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWriteBufferRequeuesTransientFailures(t *testing.T) {
+	var mu sync.Mutex
+	written := map[string]int{}
+	busy := true
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		key := strings.TrimPrefix(r.URL.Path, "/kv/")
+
+		switch {
+		case key == "rejected":
+			w.WriteHeader(http.StatusBadRequest)
+		case key == "busy" && busy:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			written[key]++
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	c, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buffer := c.NewWriteBuffer(WriteBufferOptions{FlushInterval: time.Hour})
+	defer buffer.Close(context.Background())
+
+	for _, key := range []string{"ok", "rejected", "busy"} {
+		if err := buffer.Put(key, []byte("v")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var batchErr *BatchError
+	if err := buffer.Flush(context.Background()); !errors.As(err, &batchErr) || len(batchErr.Errors) != 2 {
+		t.Fatalf("Flush = %v, want a BatchError for rejected and busy", err)
+	}
+
+	buffer.mu.Lock()
+	_, rejected := buffer.pending["rejected"]
+	_, requeued := buffer.pending["busy"]
+	buffer.mu.Unlock()
+
+	if rejected || !requeued {
+		t.Errorf("pending rejected = %t, busy = %t, want only busy to be buffered again", rejected, requeued)
+	}
+
+	mu.Lock()
+	busy = false
+	mu.Unlock()
+
+	if err := buffer.Flush(context.Background()); err != nil {
+		t.Fatalf("second Flush = %v, want nil", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if written["ok"] != 1 || written["busy"] != 1 {
+		t.Errorf("written = %v, want ok and busy once each", written)
+	}
+}